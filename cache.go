@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/markscribe/scribe"
+)
+
+// defaultCacheTTL is how long cached responses stay valid, unless configured
+// otherwise with -cache-ttl.
+const defaultCacheTTL = time.Hour
+
+// durationFlag holds durations by source, like the cache TTLs set with
+// -cache-ttl. The empty source holds the default for all sources.
+type durationFlag map[string]time.Duration
+
+func (f durationFlag) String() string {
+	s := make([]string, 0, len(f))
+	for source, ttl := range f {
+		if len(source) == 0 {
+			s = append(s, ttl.String())
+			continue
+		}
+		s = append(s, source+"="+ttl.String())
+	}
+	sort.Strings(s)
+	return strings.Join(s, ",")
+}
+
+// Set parses either a duration, which applies to all sources, or a
+// source=duration pair.
+func (f durationFlag) Set(s string) error {
+	source, d, ok := strings.Cut(s, "=")
+	if !ok {
+		source, d = "", s
+	}
+
+	ttl, err := time.ParseDuration(d)
+	if err != nil {
+		return err
+	}
+	f[source] = ttl
+	return nil
+}
+
+// cacheEntry is a response stored in the cache.
+type cacheEntry struct {
+	StoredAt   time.Time
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// cacheTransport serves responses for the requests of a source from an
+// on-disk cache, until they are older than the TTL returned by ttl. Only
+// successful responses are stored, and GraphQL mutations are never cached.
+type cacheTransport struct {
+	dir string
+	// identity identifies the credentials of the source, for sources whose
+	// Authorization header changes with every run, like the session token of
+	// a Literal.club login. Without it, requests are told apart by their
+	// Authorization header.
+	identity string
+	ttl      func() time.Duration
+	// refresh bypasses the cache, but still stores new responses
+	refresh bool
+	next    http.RoundTripper
+}
+
+func newCacheTransport(dir, identity string, ttl func() time.Duration, refresh bool, next http.RoundTripper) *cacheTransport {
+	return &cacheTransport{
+		dir:      dir,
+		identity: identity,
+		ttl:      ttl,
+		refresh:  refresh,
+		next:     next,
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ttl := t.ttl()
+	if ttl <= 0 {
+		return t.next.RoundTrip(req)
+	}
+	if req.Method != http.MethodGet && req.Method != http.MethodPost {
+		return t.next.RoundTrip(req)
+	}
+
+	body, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	// mutations change things, and their responses may hold credentials
+	if isGraphQLMutation(body) {
+		return t.next.RoundTrip(req)
+	}
+
+	key := t.key(req, body)
+	path := filepath.Join(t.dir, key+".json")
+
+	if !t.refresh {
+		if e, ok := t.load(path, ttl); ok {
+			scribe.RecordRequest(req.Context(), true)
+			return e.response(req), nil
+		}
+	}
+	scribe.RecordRequest(req.Context(), false)
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint: errcheck
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	if !hasGraphQLErrors(respBody) {
+		t.store(path, cacheEntry{
+			StoredAt:   time.Now(),
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header,
+			Body:       respBody,
+		})
+	}
+	return resp, nil
+}
+
+// key identifies req with body. Besides the URL and body, it includes the
+// credentials, so different users never share cached responses.
+func (t *cacheTransport) key(req *http.Request, body []byte) string {
+	credentials := t.identity
+	if len(credentials) == 0 {
+		credentials = req.Header.Get("Authorization")
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n%s\n", req.Method, req.URL, credentials)
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// requestBody reads the body of req, leaving it in place to be sent.
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close() //nolint: errcheck
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+func (t *cacheTransport) load(path string, ttl time.Duration) (cacheEntry, bool) {
+	var e cacheEntry
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return e, false
+	}
+	if err := json.Unmarshal(b, &e); err != nil {
+		return e, false
+	}
+	if time.Since(e.StoredAt) > ttl {
+		return e, false
+	}
+	return e, true
+}
+
+// store writes e to the cache. The cache is only an optimization, so errors
+// are ignored.
+func (t *cacheTransport) store(path string, e cacheEntry) {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(t.dir, 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, b, 0o600)
+}
+
+func (e cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode)),
+		StatusCode:    e.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// isGraphQLMutation reports whether body is a GraphQL request of a mutation.
+func isGraphQLMutation(body []byte) bool {
+	var r struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return false
+	}
+	return strings.HasPrefix(strings.TrimSpace(r.Query), "mutation")
+}
+
+// hasGraphQLErrors reports whether body is a GraphQL response containing
+// errors. GraphQL APIs report those with a successful status code.
+func hasGraphQLErrors(body []byte) bool {
+	var r struct {
+		Errors json.RawMessage `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return false
+	}
+	return len(r.Errors) > 0 && string(r.Errors) != "null"
+}
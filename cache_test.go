@@ -0,0 +1,157 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheTransport(t *testing.T) {
+	tests := []struct {
+		name string
+		// query is the GraphQL query sent, {viewer{login}} if empty
+		query  string
+		status int
+		body   string
+		// expire makes the first response expire before the second request
+		expire  bool
+		refresh bool
+		// noStore means nothing may get written to the cache
+		noStore bool
+		// want is the number of requests that reach the server
+		want int32
+	}{
+		{name: "cached", status: http.StatusOK, body: `{"data":{}}`, want: 1},
+		{name: "expired", status: http.StatusOK, body: `{"data":{}}`, expire: true, want: 2},
+		{name: "refresh", status: http.StatusOK, body: `{"data":{}}`, refresh: true, want: 2},
+		{name: "server error", status: http.StatusBadGateway, body: "bad gateway", want: 2},
+		{name: "not found", status: http.StatusNotFound, body: "not found", want: 2},
+		{name: "graphql errors", status: http.StatusOK, body: `{"data":null,"errors":[{"message":"boom"}]}`, want: 2},
+		{name: "graphql null errors", status: http.StatusOK, body: `{"data":{},"errors":null}`, want: 1},
+		{name: "mutation", query: "mutation{login(email:\\\"a\\\"){token}}", status: http.StatusOK, body: `{"data":{"login":{"token":"secret"}}}`, noStore: true, want: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body) //nolint: errcheck
+			}))
+			defer srv.Close()
+
+			var ttl atomic.Int64
+			ttl.Store(int64(time.Hour))
+			dir := t.TempDir()
+			c := &http.Client{Transport: newCacheTransport(dir, "", func() time.Duration {
+				return time.Duration(ttl.Load())
+			}, tt.refresh, http.DefaultTransport)}
+
+			query := tt.query
+			if len(query) == 0 {
+				query = "{viewer{login}}"
+			}
+			for i := 0; i < 2; i++ {
+				resp, err := c.Post(srv.URL, "application/json", strings.NewReader(`{"query":"`+query+`"}`))
+				if err != nil {
+					t.Fatal(err)
+				}
+				b, _ := io.ReadAll(resp.Body)
+				resp.Body.Close() //nolint: errcheck
+				if resp.StatusCode != tt.status || string(b) != tt.body {
+					t.Errorf("request %d got %d %q, want %d %q", i+1, resp.StatusCode, b, tt.status, tt.body)
+				}
+
+				if tt.expire {
+					ttl.Store(int64(time.Nanosecond))
+					time.Sleep(time.Millisecond)
+				}
+			}
+
+			if n := requests.Load(); n != tt.want {
+				t.Errorf("server got %d requests, want %d", n, tt.want)
+			}
+			if tt.noStore {
+				if entries, _ := os.ReadDir(dir); len(entries) > 0 {
+					t.Errorf("cached %d responses, want none", len(entries))
+				}
+			}
+		})
+	}
+}
+
+func TestCacheTransportKey(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		io.WriteString(w, `{"data":{}}`) //nolint: errcheck
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: newCacheTransport(t.TempDir(), "", func() time.Duration {
+		return time.Hour
+	}, false, http.DefaultTransport)}
+
+	// different queries and different credentials never share responses
+	for _, r := range []struct{ body, auth string }{
+		{`{"query":"a"}`, "bearer one"},
+		{`{"query":"b"}`, "bearer one"},
+		{`{"query":"a"}`, "bearer two"},
+		{`{"query":"a"}`, "bearer one"},
+	} {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(r.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", r.auth)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close() //nolint: errcheck
+	}
+
+	if n := requests.Load(); n != 3 {
+		t.Errorf("server got %d requests, want 3", n)
+	}
+}
+
+func TestCacheTransportIdentity(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		io.WriteString(w, `{"data":{}}`) //nolint: errcheck
+	}))
+	defer srv.Close()
+
+	// the same settings share responses, whatever session token they got
+	dir := t.TempDir()
+	for _, r := range []struct{ identity, auth string }{
+		{"email=a\n", "bearer one"},
+		{"email=a\n", "bearer two"},
+		{"email=b\n", "bearer three"},
+	} {
+		c := &http.Client{Transport: newCacheTransport(dir, r.identity, func() time.Duration {
+			return time.Hour
+		}, false, http.DefaultTransport)}
+		req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"query":"a"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", r.auth)
+		resp, err := c.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close() //nolint: errcheck
+	}
+
+	if n := requests.Load(); n != 2 {
+		t.Errorf("server got %d requests, want 2", n)
+	}
+}
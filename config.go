@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// defaultConfig is the config file markscribe looks for when it's started
+// without a template.
+const defaultConfig = "markscribe.yaml"
+
+// config describes a set of jobs rendered in a single run.
+type config struct {
+	Jobs []job `yaml:"jobs"`
+}
+
+// job renders a single template to an output.
+type job struct {
+	// Template is the path of the template to render.
+	Template string `yaml:"template"`
+	// Output is the path the result gets written to. Empty means stdout.
+	Output string `yaml:"output"`
+	// Include are glob patterns of files with templates to make available,
+	// in addition to those of -include.
+	Include []string `yaml:"include"`
+	// Splice only replaces the marked sections of Output.
+	Splice bool `yaml:"splice"`
+	// Owner is the GitHub user that user-scoped functions like recentStars
+	// describe. Defaults to the owner of the GitHub token.
+	Owner string `yaml:"owner"`
+	// Vars are the template's variables. -vars and -var override them.
+	Vars map[string]interface{} `yaml:"vars"`
+
+	// set by the template's front matter
+	cacheTTL durationFlag
+	sources  []string
+}
+
+func (j job) String() string {
+	if len(j.Output) == 0 {
+		return j.Template + " -> stdout"
+	}
+	return j.Template + " -> " + j.Output
+}
+
+// loadConfig reads the config file at path. Relative paths of its jobs are
+// resolved relative to the directory of the config file.
+func loadConfig(path string) (config, error) {
+	var cfg config
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil {
+		return cfg, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(cfg.Jobs) == 0 {
+		return cfg, fmt.Errorf("%s: no jobs defined", path)
+	}
+
+	dir := filepath.Dir(path)
+	for i, j := range cfg.Jobs {
+		if len(j.Template) == 0 {
+			return cfg, fmt.Errorf("%s: job %d has no template", path, i+1)
+		}
+		if j.Splice && len(j.Output) == 0 {
+			return cfg, fmt.Errorf("%s: job %d uses splice but has no output", path, i+1)
+		}
+
+		cfg.Jobs[i].Template = resolvePath(dir, j.Template)
+		if len(j.Output) > 0 {
+			cfg.Jobs[i].Output = resolvePath(dir, j.Output)
+		}
+		for k, pattern := range j.Include {
+			cfg.Jobs[i].Include[k] = resolvePath(dir, pattern)
+		}
+	}
+
+	return cfg, nil
+}
+
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	abs := filepath.Join(os.TempDir(), "README.md")
+
+	tests := []struct {
+		name   string
+		config string
+		// want are the jobs, with $dir/ standing for the config's directory
+		want  []job
+		error string
+	}{
+		{
+			name: "relative paths",
+			config: `jobs:
+  - template: templates/README.md.tpl
+    output: ../README.md
+    include: [partials/*.tpl]
+  - template: blog.tpl
+`,
+			want: []job{
+				{Template: "$dir/templates/README.md.tpl", Output: "$dir/../README.md", Include: []string{"$dir/partials/*.tpl"}},
+				{Template: "$dir/blog.tpl"},
+			},
+		},
+		{
+			name: "absolute paths",
+			config: `jobs:
+  - template: README.md.tpl
+    output: '` + abs + `'
+    splice: true
+    owner: muesli
+    vars:
+      name: Christian
+`,
+			want: []job{
+				{Template: "$dir/README.md.tpl", Output: abs, Splice: true, Owner: "muesli", Vars: map[string]interface{}{"name": "Christian"}},
+			},
+		},
+		{
+			name:   "no jobs",
+			config: "jobs: []\n",
+			error:  "no jobs defined",
+		},
+		{
+			name:   "empty",
+			config: "",
+			error:  "EOF",
+		},
+		{
+			name:   "no template",
+			config: "jobs:\n  - template: a.tpl\n  - output: b.md\n",
+			error:  "job 2 has no template",
+		},
+		{
+			name:   "splice without output",
+			config: "jobs:\n  - template: a.tpl\n    splice: true\n",
+			error:  "job 1 uses splice but has no output",
+		},
+		{
+			name:   "unknown field",
+			config: "jobs:\n  - template: a.tpl\n    ouptut: README.md\n",
+			error:  "field ouptut not found",
+		},
+		{
+			name:   "unknown top-level field",
+			config: "job:\n  - template: a.tpl\n",
+			error:  "field job not found",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "site")
+			if err := os.Mkdir(dir, 0o700); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(dir, defaultConfig)
+			if err := os.WriteFile(path, []byte(tt.config), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := loadConfig(path)
+			if len(tt.error) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.error) {
+					t.Fatalf("error = %v, want %q", err, tt.error)
+				}
+				if !strings.HasPrefix(err.Error(), path+": ") {
+					t.Errorf("error = %v, want it to name the config file", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expand := func(path string) string {
+				if rel, ok := strings.CutPrefix(path, "$dir/"); ok {
+					return filepath.Join(dir, rel)
+				}
+				return path
+			}
+			for i, j := range tt.want {
+				tt.want[i].Template = expand(j.Template)
+				tt.want[i].Output = expand(j.Output)
+				for k, pattern := range j.Include {
+					tt.want[i].Include[k] = expand(pattern)
+				}
+			}
+			if !reflect.DeepEqual(cfg.Jobs, tt.want) {
+				t.Errorf("jobs = %+v, want %+v", cfg.Jobs, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/charmbracelet/markscribe/scribe"
+	"gopkg.in/yaml.v3"
+)
+
+// dataCommand is the subcommand printing the result of a data function.
+const dataCommand = "data"
+
+// runData calls the data function named by the first of args with the rest
+// of them and prints the result to stdout. It returns the exit status.
+func runData(ctx context.Context, s *scribe.Scribe, args []string) int {
+	fs := flag.NewFlagSet(dataCommand, flag.ContinueOnError)
+	format := fs.String("format", "json", "print the result as json or yaml")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: markscribe [flags] data [-format json|yaml] <function> [args...]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 1
+	}
+	if *format != "json" && *format != "yaml" {
+		fmt.Fprintf(os.Stderr, "unknown format %q, expected json or yaml\n", *format)
+		return 1
+	}
+
+	callArgs := make([]interface{}, 0, fs.NArg()-1)
+	for _, a := range fs.Args()[1:] {
+		callArgs = append(callArgs, a)
+	}
+	res, err := s.Call(ctx, fs.Arg(0), callArgs...)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if err := writeData(os.Stdout, res, *format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// writeData writes v to w in format, json or yaml. Either way, fields are
+// named like templates access them.
+func writeData(w io.Writer, v interface{}, format string) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	if format == "json" {
+		_, err := buf.WriteTo(w)
+		return err
+	}
+
+	// JSON is YAML, so this keeps the names and order of the fields
+	var doc yaml.Node
+	if err := yaml.Unmarshal(buf.Bytes(), &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+	yenc := yaml.NewEncoder(w)
+	yenc.SetIndent(2)
+	if err := yenc.Encode(&doc); err != nil {
+		return err
+	}
+	return yenc.Close()
+}
+
+// blockStyle formats n and its children in YAML's block style, instead of
+// the flow style of JSON.
+func blockStyle(n *yaml.Node) {
+	n.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, c := range n.Content {
+		blockStyle(c)
+	}
+}
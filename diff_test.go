@@ -0,0 +1,107 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		// want is the edit script, one op per line
+		want string
+	}{
+		{"equal", "a\nb\n", "a\nb\n", "  "},
+		{"both empty", "", "", ""},
+		{"insert all", "", "a\nb\n", "++"},
+		{"delete all", "a\nb\n", "", "--"},
+		{"replace", "a\nb\nc\n", "a\nB\nc\n", " -+ "},
+		{"insert middle", "a\nc\n", "a\nb\nc\n", " + "},
+		{"delete middle", "a\nb\nc\n", "a\nc\n", " - "},
+		{"move", "a\nb\nc\n", "b\nc\na\n", "-  +"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := splitLines(tt.a), splitLines(tt.b)
+			edits := diffLines(a, b)
+
+			var ops, oldText, newText strings.Builder
+			for _, e := range edits {
+				ops.WriteByte(e.op)
+				if e.op != '+' {
+					oldText.WriteString(e.line)
+				}
+				if e.op != '-' {
+					newText.WriteString(e.line)
+				}
+			}
+			if ops.String() != tt.want {
+				t.Errorf("ops = %q, want %q", ops.String(), tt.want)
+			}
+			// the script has to turn a into b
+			if oldText.String() != tt.a || newText.String() != tt.b {
+				t.Errorf("edits turn %q into %q, want %q into %q", oldText.String(), newText.String(), tt.a, tt.b)
+			}
+		})
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	numbers := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+
+	tests := []struct {
+		name     string
+		old, new string
+		want     string
+	}{
+		{
+			name: "change",
+			old:  "a\nb\nc\n",
+			new:  "a\nB\nc\n",
+			want: "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		{
+			name: "new file",
+			old:  "",
+			new:  "x\ny\n",
+			want: "@@ -0,0 +1,2 @@\n+x\n+y\n",
+		},
+		{
+			name: "emptied file",
+			old:  "x\n",
+			new:  "",
+			want: "@@ -1 +0,0 @@\n-x\n",
+		},
+		{
+			name: "no newline at end",
+			old:  "a\nb",
+			new:  "a\nc",
+			want: "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			name: "separate hunks",
+			old:  numbers,
+			new:  strings.Replace(strings.Replace(numbers, "1\n", "x\n", 1), "12\n", "y\n", 1),
+			want: "@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n",
+		},
+		{
+			name: "merged hunks",
+			old:  numbers,
+			new:  strings.Replace(strings.Replace(numbers, "2\n", "x\n", 1), "8\n", "y\n", 1),
+			want: "@@ -1,11 +1,11 @@\n 1\n-2\n+x\n 3\n 4\n 5\n 6\n 7\n-8\n+y\n 9\n 10\n 11\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := unifiedDiff(&b, "README.md", tt.old, tt.new); err != nil {
+				t.Fatal(err)
+			}
+			want := "--- a/README.md\n+++ b/README.md\n" + tt.want
+			if b.String() != want {
+				t.Errorf("unifiedDiff() =\n%s\nwant\n%s", b.String(), want)
+			}
+		})
+	}
+}
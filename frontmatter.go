@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/markscribe/scribe"
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterDelim opens and closes the front matter of a template.
+const frontMatterDelim = "---"
+
+// frontMatter holds the settings a template declares at its top:
+//
+//	---
+//	owner: muesli
+//	output: README.md
+//	vars:
+//	  count: 5
+//	cache_ttl: [2h, rss=30m]
+//	sources: [github, rss]
+//	---
+//
+// They serve as defaults, which flags and config files override.
+type frontMatter struct {
+	Output   string                 `yaml:"output"`
+	Splice   bool                   `yaml:"splice"`
+	Owner    string                 `yaml:"owner"`
+	Include  []string               `yaml:"include"`
+	Vars     map[string]interface{} `yaml:"vars"`
+	CacheTTL []string               `yaml:"cache_ttl"`
+	Sources  []string               `yaml:"sources"`
+}
+
+// splitFrontMatter separates the front matter from the rest of a template.
+// The front matter is replaced by a template comment spanning as many lines,
+// so line numbers in errors still match the template file.
+func splitFrontMatter(tpl []byte) ([]byte, []byte) {
+	first, rest, ok := bytes.Cut(tpl, []byte("\n"))
+	if !ok || string(bytes.TrimRight(first, "\r")) != frontMatterDelim {
+		return nil, tpl
+	}
+
+	var fm []byte
+	lines := 1
+	for len(rest) > 0 {
+		var line []byte
+		line, rest, _ = bytes.Cut(rest, []byte("\n"))
+		lines++
+		if string(bytes.TrimRight(line, "\r")) == frontMatterDelim {
+			comment := "{{/*" + strings.Repeat("\n", lines) + "*/}}"
+			return fm, append([]byte(comment), rest...)
+		}
+		fm = append(append(fm, line...), '\n')
+	}
+
+	// never closed, so it's not front matter
+	return nil, tpl
+}
+
+// parseFrontMatter parses the front matter of a template.
+func parseFrontMatter(b []byte) (frontMatter, error) {
+	var fm frontMatter
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&fm); err != nil {
+		return fm, err
+	}
+
+	for _, name := range fm.Sources {
+		if _, ok := registeredSource(name); !ok {
+			return fm, fmt.Errorf("unknown source %q", name)
+		}
+	}
+	return fm, nil
+}
+
+// applyFrontMatter reads the front matter of the template of j and fills
+// in the settings j doesn't set itself. Relative paths are resolved relative
+// to the template.
+func applyFrontMatter(j job) (job, error) {
+	b, err := os.ReadFile(j.Template)
+	if err != nil {
+		return j, fmt.Errorf("can't read file: %w", err)
+	}
+
+	fmIn, _ := splitFrontMatter(b)
+	if fmIn == nil {
+		return j, nil
+	}
+	fm, err := parseFrontMatter(fmIn)
+	if err != nil {
+		return j, fmt.Errorf("can't parse front matter of %s: %w", j.Template, err)
+	}
+
+	dir := filepath.Dir(j.Template)
+	if len(j.Output) == 0 && len(fm.Output) > 0 {
+		j.Output = resolvePath(dir, fm.Output)
+		j.Splice = j.Splice || fm.Splice
+	}
+	if len(j.Owner) == 0 {
+		j.Owner = fm.Owner
+	}
+	for _, pattern := range fm.Include {
+		j.Include = append(j.Include, resolvePath(dir, pattern))
+	}
+
+	vars := map[string]interface{}{}
+	for k, v := range fm.Vars {
+		vars[k] = v
+	}
+	for k, v := range j.Vars {
+		vars[k] = v
+	}
+	j.Vars = vars
+
+	j.cacheTTL = durationFlag{}
+	for _, ttl := range fm.CacheTTL {
+		if err := j.cacheTTL.Set(ttl); err != nil {
+			return j, fmt.Errorf("front matter of %s: cache_ttl: %w", j.Template, err)
+		}
+	}
+	j.sources = fm.Sources
+
+	return j, nil
+}
+
+// registeredSource returns a new instance of the registered source called
+// name.
+func registeredSource(name string) (scribe.Source, bool) {
+	for _, src := range scribe.Sources() {
+		if src.Name() == name {
+			return src, true
+		}
+	}
+	return nil, false
+}
+
+// checkCredentials makes sure all sources needed by j are enabled and have
+// their required settings set.
+func checkCredentials(j job) error {
+	var missing []string
+	for _, name := range j.sources {
+		if !sourceEnabled(name) {
+			return fmt.Errorf("%s needs source %q, which is disabled", j.Template, name)
+		}
+
+		src, _ := registeredSource(name)
+		for _, s := range src.Settings() {
+			if s.Required && len(os.Getenv(s.Env)) == 0 {
+				missing = append(missing, s.Env)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%s needs %s to be set", j.Template, strings.Join(missing, ", "))
+	}
+	return nil
+}
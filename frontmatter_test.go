@@ -0,0 +1,195 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+func TestSplitFrontMatter(t *testing.T) {
+	tests := []struct {
+		name string
+		tpl  string
+		fm   string
+		rest string
+		// body is the rendered rest
+		body string
+	}{
+		{
+			name: "none",
+			tpl:  "Hello {{.name}}\n",
+			rest: "Hello {{.name}}\n",
+		},
+		{
+			name: "front matter",
+			tpl:  "---\noutput: README.md\nowner: muesli\n---\nHello\n",
+			fm:   "output: README.md\nowner: muesli\n",
+			rest: "{{/*\n\n\n\n*/}}Hello\n",
+			body: "Hello\n",
+		},
+		{
+			name: "empty",
+			tpl:  "---\n---\nHello\n",
+			rest: "{{/*\n\n*/}}Hello\n",
+		},
+		{
+			name: "crlf",
+			tpl:  "---\r\noutput: README.md\r\n---\r\nHello\r\n",
+			fm:   "output: README.md\r\n",
+			rest: "{{/*\n\n\n*/}}Hello\r\n",
+			body: "Hello\r\n",
+		},
+		{
+			name: "leading whitespace",
+			tpl:  "---\nowner: muesli\n---\n\n\n    indented\n",
+			fm:   "owner: muesli\n",
+			rest: "{{/*\n\n\n*/}}\n\n    indented\n",
+			body: "\n\n    indented\n",
+		},
+		{
+			name: "never closed",
+			tpl:  "---\noutput: README.md\nHello\n",
+			rest: "---\noutput: README.md\nHello\n",
+		},
+		{
+			name: "not at the top",
+			tpl:  "Hello\n---\noutput: README.md\n---\n",
+			rest: "Hello\n---\noutput: README.md\n---\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fm, rest := splitFrontMatter([]byte(tt.tpl))
+			if string(fm) != tt.fm || string(rest) != tt.rest {
+				t.Errorf("splitFrontMatter() = %q, %q, want %q, %q", fm, rest, tt.fm, tt.rest)
+			}
+			// the front matter renders as nothing
+			if len(tt.body) > 0 {
+				tpl, err := template.New("").Parse(string(rest))
+				if err != nil {
+					t.Fatal(err)
+				}
+				var b strings.Builder
+				if err := tpl.Execute(&b, nil); err != nil {
+					t.Fatal(err)
+				}
+				if b.String() != tt.body {
+					t.Errorf("rendered %q, want %q", b.String(), tt.body)
+				}
+			}
+			// line numbers of the template stay the same
+			if len(tt.fm) > 0 && strings.Count(string(rest), "\n") != strings.Count(tt.tpl, "\n") {
+				t.Errorf("rest has %d lines, want %d", strings.Count(string(rest), "\n"), strings.Count(tt.tpl, "\n"))
+			}
+		})
+	}
+}
+
+func TestApplyFrontMatter(t *testing.T) {
+	tests := []struct {
+		name  string
+		fm    string
+		job   job
+		want  job
+		error string
+	}{
+		{
+			name: "defaults",
+			fm:   "output: out/README.md\nsplice: true\nowner: muesli\ninclude: [partials/*.tpl]\nvars:\n  count: 5\ncache_ttl: [2h, rss=30m]\nsources: [github, rss]\n",
+			want: job{
+				Output:   "out/README.md",
+				Splice:   true,
+				Owner:    "muesli",
+				Include:  []string{"partials/*.tpl"},
+				Vars:     map[string]interface{}{"count": 5},
+				cacheTTL: durationFlag{"": 2 * time.Hour, "rss": 30 * time.Minute},
+				sources:  []string{"github", "rss"},
+			},
+		},
+		{
+			name: "job settings win",
+			fm:   "output: README.md\nsplice: true\nowner: muesli\nvars:\n  count: 5\n  title: Hi\n",
+			job: job{
+				Output: "/elsewhere.md",
+				Owner:  "someone",
+				Vars:   map[string]interface{}{"count": 10},
+			},
+			want: job{
+				Output:   "/elsewhere.md",
+				Owner:    "someone",
+				Vars:     map[string]interface{}{"count": 10, "title": "Hi"},
+				cacheTTL: durationFlag{},
+			},
+		},
+		{
+			name:  "unknown setting",
+			fm:    "outptu: README.md\n",
+			error: "field outptu not found",
+		},
+		{
+			name:  "unknown source",
+			fm:    "sources: [myspace]\n",
+			error: `unknown source "myspace"`,
+		},
+		{
+			name:  "invalid cache ttl",
+			fm:    "cache_ttl: [soon]\n",
+			error: "cache_ttl",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tpl := filepath.Join(dir, "template.tpl")
+			if err := os.WriteFile(tpl, []byte("---\n"+tt.fm+"---\nHello\n"), 0o644); err != nil { //nolint: gosec
+				t.Fatal(err)
+			}
+
+			j := tt.job
+			j.Template = tpl
+			got, err := applyFrontMatter(j)
+			if len(tt.error) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.error) {
+					t.Fatalf("err = %v, want %q", err, tt.error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// relative paths are resolved relative to the template
+			want := tt.want
+			want.Template = tpl
+			if len(want.Output) > 0 && !filepath.IsAbs(want.Output) {
+				want.Output = filepath.Join(dir, want.Output)
+			}
+			for i, pattern := range want.Include {
+				want.Include[i] = filepath.Join(dir, pattern)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("applyFrontMatter() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyFrontMatterWithout(t *testing.T) {
+	tpl := filepath.Join(t.TempDir(), "template.tpl")
+	if err := os.WriteFile(tpl, []byte("Hello\n"), 0o644); err != nil { //nolint: gosec
+		t.Fatal(err)
+	}
+
+	j := job{Template: tpl, Output: "README.md"}
+	got, err := applyFrontMatter(j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, j) {
+		t.Errorf("applyFrontMatter() = %+v, want %+v", got, j)
+	}
+}
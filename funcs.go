@@ -0,0 +1,167 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/charmbracelet/markscribe/scribe"
+)
+
+// funcsCommand is the subcommand listing the template functions.
+const funcsCommand = "funcs"
+
+// runFuncs prints the catalog of template functions and the types they
+// return to stdout. It returns the exit status.
+func runFuncs(args []string) int {
+	fs := flag.NewFlagSet(funcsCommand, flag.ContinueOnError)
+	format := fs.String("format", "text", "print the catalog as text or markdown")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: markscribe funcs [-format text|markdown]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return 1
+	}
+
+	funcs := scribe.New(scribe.Options{}).Catalog()
+	types := resultTypes(funcs)
+	switch *format {
+	case "text":
+		writeFuncsText(os.Stdout, funcs, types)
+	case "markdown":
+		writeFuncsMarkdown(os.Stdout, funcs, types)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown format %q, expected text or markdown\n", *format)
+		return 1
+	}
+	return 0
+}
+
+// writeFuncsText writes funcs and types to w as plain text.
+func writeFuncsText(w io.Writer, funcs []scribe.FuncInfo, types []reflect.Type) {
+	for i, f := range funcs {
+		if i == 0 || f.Source != funcs[i-1].Source {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintln(w, sourceTitle(f))
+		}
+
+		fmt.Fprintf(w, "\n  %s -> %s\n", f.Signature(), scribe.TypeName(f.Result))
+		if len(f.Summary) > 0 {
+			fmt.Fprintf(w, "      %s\n", f.Summary)
+		}
+		if len(f.Scopes) > 0 {
+			fmt.Fprintf(w, "      scopes: %s\n", strings.Join(f.Scopes, ", "))
+		}
+		if len(f.Example) > 0 {
+			fmt.Fprintf(w, "      example: %s\n", f.Example)
+		}
+	}
+
+	fmt.Fprintln(w, "\ntypes")
+	for _, t := range types {
+		fmt.Fprintf(w, "\n  %s\n", scribe.TypeName(t))
+		tw := tabwriter.NewWriter(w, 0, 4, 1, ' ', 0)
+		for _, f := range exportedFields(t) {
+			fmt.Fprintf(tw, "      %s\t%s\n", f.Name, scribe.TypeName(f.Type))
+		}
+		tw.Flush() //nolint: errcheck
+	}
+}
+
+// writeFuncsMarkdown writes funcs and types to w as Markdown.
+func writeFuncsMarkdown(w io.Writer, funcs []scribe.FuncInfo, types []reflect.Type) {
+	fmt.Fprint(w, "# Template Functions\n\n")
+	fmt.Fprint(w, "<!-- generated with `markscribe funcs -format markdown`, don't edit -->\n")
+	for i, f := range funcs {
+		if i == 0 || f.Source != funcs[i-1].Source {
+			fmt.Fprintf(w, "\n## %s\n", sourceTitle(f))
+		}
+
+		fmt.Fprintf(w, "\n### %s\n\n", f.Name)
+		fmt.Fprintf(w, "```\n%s -> %s\n```\n", f.Signature(), scribe.TypeName(f.Result))
+		if len(f.Summary) > 0 {
+			fmt.Fprintf(w, "\n%s\n", f.Summary)
+		}
+		if len(f.Scopes) > 0 {
+			fmt.Fprintf(w, "\nScopes: `%s`\n", strings.Join(f.Scopes, "`, `"))
+		}
+		if len(f.Example) > 0 {
+			fmt.Fprintf(w, "\n```\n%s\n```\n", f.Example)
+		}
+	}
+
+	fmt.Fprint(w, "\n## Types\n")
+	for _, t := range types {
+		fmt.Fprintf(w, "\n### %s\n\n", scribe.TypeName(t))
+		fmt.Fprint(w, "| Field | Type |\n| --- | --- |\n")
+		for _, f := range exportedFields(t) {
+			fmt.Fprintf(w, "| %s | `%s` |\n", f.Name, scribe.TypeName(f.Type))
+		}
+	}
+}
+
+// sourceTitle names the source of f, with the credentials it requires.
+func sourceTitle(f scribe.FuncInfo) string {
+	switch {
+	case len(f.Source) == 0:
+		return "helpers"
+	case len(f.Credentials) == 0:
+		return f.Source
+	}
+	return fmt.Sprintf("%s (requires %s)", f.Source, strings.Join(f.Credentials, ", "))
+}
+
+// resultTypes returns the struct types funcs return, and the struct types
+// of their fields, sorted by name.
+func resultTypes(funcs []scribe.FuncInfo) []reflect.Type {
+	seen := map[reflect.Type]bool{}
+	var types []reflect.Type
+	var add func(t reflect.Type)
+	add = func(t reflect.Type) {
+		for t.Kind() == reflect.Slice || t.Kind() == reflect.Ptr || t.Kind() == reflect.Map {
+			t = t.Elem()
+		}
+		// times are documented well enough elsewhere
+		if t.Kind() != reflect.Struct || t == reflect.TypeOf(time.Time{}) || seen[t] {
+			return
+		}
+		seen[t] = true
+		types = append(types, t)
+		for _, f := range exportedFields(t) {
+			add(f.Type)
+		}
+	}
+	for _, f := range funcs {
+		add(f.Result)
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+		return scribe.TypeName(types[i]) < scribe.TypeName(types[j])
+	})
+	return types
+}
+
+// exportedFields returns the fields of the struct type t templates can
+// access.
+func exportedFields(t reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, t.NumField())
+	for _, f := range reflect.VisibleFields(t) {
+		if f.IsExported() && !f.Anonymous {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// defaultCommitMessage is the template of the commit message, unless set
+// with -commit-message.
+const defaultCommitMessage = `Update {{join .Files ", "}}`
+
+// commitData is what the commit message template gets executed with.
+type commitData struct {
+	// Files are the changed outputs, relative to the repository.
+	Files []string
+	Date  time.Time
+}
+
+// commitOutputs commits the outputs at paths that differ from what's
+// committed to the git repositories they're in, and pushes the commits with
+// -push.
+func commitOutputs(ctx context.Context, paths []string) error {
+	repos := map[string]*git.Repository{}
+	files := map[string][]string{}
+	for _, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		repo, err := git.PlainOpenWithOptions(filepath.Dir(abs), &git.PlainOpenOptions{DetectDotGit: true})
+		if err != nil {
+			return fmt.Errorf("can't open the git repository of %s: %w", path, err)
+		}
+		wt, err := repo.Worktree()
+		if err != nil {
+			return fmt.Errorf("can't open the git repository of %s: %w", path, err)
+		}
+
+		root := wt.Filesystem.Root()
+		rel, err := filepath.Rel(root, abs)
+		if err != nil {
+			return err
+		}
+		repos[root] = repo
+		files[root] = append(files[root], filepath.ToSlash(rel))
+	}
+
+	roots := make([]string, 0, len(repos))
+	for root := range repos {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+
+	for _, root := range roots {
+		if err := commitFiles(ctx, repos[root], files[root]); err != nil {
+			return fmt.Errorf("can't commit to %s: %w", root, err)
+		}
+	}
+	return nil
+}
+
+// commitFiles commits the files of repo that changed, if any. It refuses to
+// if anything else is staged, which would get committed along with them.
+func commitFiles(ctx context.Context, repo *git.Repository, files []string) error {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return err
+	}
+
+	// the commit would include anything else that's staged
+	var staged []string
+	for path, s := range status {
+		if s.Staging != git.Unmodified && s.Staging != git.Untracked && !slices.Contains(files, path) {
+			staged = append(staged, path)
+		}
+	}
+	if len(staged) > 0 {
+		sort.Strings(staged)
+		return fmt.Errorf("other changes are staged, commit or unstage them first: %s", strings.Join(staged, ", "))
+	}
+
+	var changed []string
+	for _, f := range files {
+		// files missing from the status are unmodified
+		if s, ok := status[f]; ok && (s.Worktree != git.Unmodified || s.Staging != git.Unmodified) {
+			changed = append(changed, f)
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	sort.Strings(changed)
+
+	for _, f := range changed {
+		if _, err := wt.Add(f); err != nil {
+			return err
+		}
+	}
+
+	author, err := commitAuthor(repo)
+	if err != nil {
+		return err
+	}
+	msg, err := commitMessage(changed, author.When)
+	if err != nil {
+		return err
+	}
+	hash, err := wt.Commit(msg, &git.CommitOptions{Author: author})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(info, "%-10s %s %s\n", "committed:", hash.String()[:7], strings.SplitN(msg, "\n", 2)[0])
+
+	if !*push {
+		return nil
+	}
+	return pushHead(ctx, repo)
+}
+
+// commitMessage executes the commit message template for the changed files.
+func commitMessage(files []string, date time.Time) (string, error) {
+	tpl, err := template.New("commit message").
+		Funcs(template.FuncMap{"join": strings.Join}).
+		Parse(*commitMsg)
+	if err != nil {
+		return "", fmt.Errorf("can't parse commit message: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tpl.Execute(&buf, commitData{Files: files, Date: date}); err != nil {
+		return "", fmt.Errorf("can't render commit message: %w", err)
+	}
+	if len(strings.TrimSpace(buf.String())) == 0 {
+		return "", errors.New("empty commit message")
+	}
+	return buf.String(), nil
+}
+
+// commitAuthor returns the author set with -commit-author, or else the user
+// configured in git.
+func commitAuthor(repo *git.Repository) (*object.Signature, error) {
+	if len(*commitAuth) > 0 {
+		addr, err := mail.ParseAddress(*commitAuth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -commit-author %q, expected \"Name <email>\"", *commitAuth)
+		}
+		return &object.Signature{Name: addr.Name, Email: addr.Address, When: time.Now()}, nil
+	}
+
+	cfg, err := repo.ConfigScoped(gitconfig.GlobalScope)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.User.Name) == 0 || len(cfg.User.Email) == 0 {
+		return nil, errors.New("no commit author: set -commit-author, or git's user.name and user.email")
+	}
+	return &object.Signature{Name: cfg.User.Name, Email: cfg.User.Email, When: time.Now()}, nil
+}
+
+// pushHead pushes the current branch of repo to its origin.
+func pushHead(ctx context.Context, repo *git.Repository) error {
+	head, err := repo.Head()
+	if err != nil {
+		return err
+	}
+	if !head.Name().IsBranch() {
+		return errors.New("can't push a detached HEAD")
+	}
+
+	remote, err := repo.Remote("origin")
+	if err != nil {
+		return fmt.Errorf("can't push: %w", err)
+	}
+	refSpec := gitconfig.RefSpec(fmt.Sprintf("%s:%s", head.Name(), head.Name()))
+	err = remote.PushContext(ctx, &git.PushOptions{
+		RefSpecs: []gitconfig.RefSpec{refSpec},
+		Auth:     pushAuth(remote.Config().URLs[0]),
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return fmt.Errorf("can't push: %w", err)
+	}
+	fmt.Fprintf(info, "%-10s %s to origin\n", "pushed:", head.Name().Short())
+	return nil
+}
+
+// pushAuth returns the credentials for pushing to remoteURL: GITHUB_TOKEN for
+// the GitHub instance markscribe talks to, and none for any other remote.
+// SSH remotes authenticate with the SSH agent.
+func pushAuth(remoteURL string) transport.AuthMethod {
+	token := os.Getenv("GITHUB_TOKEN")
+	if len(token) == 0 {
+		return nil
+	}
+	u, err := url.Parse(remoteURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Hostname() != gitHubHost() {
+		return nil
+	}
+	return &githttp.BasicAuth{Username: "x-access-token", Password: token}
+}
+
+// gitHubHost returns the host of the GitHub instance whose API is used.
+func gitHubHost() string {
+	apiURL := *gitHubURL
+	if len(apiURL) == 0 {
+		apiURL = os.Getenv("GITHUB_API_URL")
+	}
+	u, err := url.Parse(apiURL)
+	if err != nil || len(u.Hostname()) == 0 || u.Hostname() == "api.github.com" {
+		return "github.com"
+	}
+	return u.Hostname()
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// setFlag sets the flag behind p to v for the duration of the test.
+func setFlag[T any](t *testing.T, p *T, v T) {
+	t.Helper()
+	old := *p
+	*p = v
+	t.Cleanup(func() { *p = old })
+}
+
+// initRepo creates a repository in a temporary directory with a committed
+// README.md, and returns it with its directory.
+func initRepo(t *testing.T) (*git.Repository, string) {
+	t.Helper()
+	setFlag(t, &info, io.Writer(io.Discard))
+	setFlag(t, commitAuth, "Test <test@example.com>")
+	setFlag(t, commitMsg, defaultCommitMessage)
+	setFlag(t, push, false)
+
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, "README.md"), "old")
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add("README.md"); err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repo, dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil { //nolint: gosec
+		t.Fatal(err)
+	}
+}
+
+func headCommit(t *testing.T, repo *git.Repository) *object.Commit {
+	t.Helper()
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestCommitOutputs(t *testing.T) {
+	repo, dir := initRepo(t)
+	out := filepath.Join(dir, "README.md")
+	writeFile(t, out, "new")
+
+	if err := commitOutputs(context.Background(), []string{out}); err != nil {
+		t.Fatal(err)
+	}
+
+	c := headCommit(t, repo)
+	if c.Message != "Update README.md" {
+		t.Errorf("message = %q, want %q", c.Message, "Update README.md")
+	}
+	if c.Author.Email != "test@example.com" {
+		t.Errorf("author = %q, want test@example.com", c.Author.Email)
+	}
+	f, err := c.File("README.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if content, _ := f.Contents(); content != "new" {
+		t.Errorf("committed README.md = %q, want %q", content, "new")
+	}
+}
+
+func TestCommitOutputsUnchanged(t *testing.T) {
+	repo, dir := initRepo(t)
+	before := headCommit(t, repo).Hash
+
+	if err := commitOutputs(context.Background(), []string{filepath.Join(dir, "README.md")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if after := headCommit(t, repo).Hash; after != before {
+		t.Errorf("HEAD moved to %s, want no commit", after)
+	}
+}
+
+func TestCommitOutputsOtherChangesStaged(t *testing.T) {
+	repo, dir := initRepo(t)
+	before := headCommit(t, repo).Hash
+
+	writeFile(t, filepath.Join(dir, "README.md"), "new")
+	writeFile(t, filepath.Join(dir, "secret.txt"), "not for the README commit")
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add("secret.txt"); err != nil {
+		t.Fatal(err)
+	}
+
+	err = commitOutputs(context.Background(), []string{filepath.Join(dir, "README.md")})
+	if err == nil || !strings.Contains(err.Error(), "secret.txt") {
+		t.Fatalf("err = %v, want an error naming secret.txt", err)
+	}
+	if after := headCommit(t, repo).Hash; after != before {
+		t.Errorf("HEAD moved to %s, want no commit", after)
+	}
+}
+
+func TestCommitOutputsPush(t *testing.T) {
+	repo, dir := initRepo(t)
+	setFlag(t, push, true)
+
+	remoteDir := t.TempDir()
+	remote, err := git.PlainInit(remoteDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{
+		Name: "origin",
+		URLs: []string{"file://" + filepath.ToSlash(remoteDir)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(dir, "README.md"), "new")
+	if err := commitOutputs(context.Background(), []string{filepath.Join(dir, "README.md")}); err != nil {
+		t.Fatal(err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := remote.Reference(head.Name(), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ref.Hash() != head.Hash() {
+		t.Errorf("remote %s = %s, want %s", head.Name(), ref.Hash(), head.Hash())
+	}
+}
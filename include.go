@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// includeFlag holds the glob patterns set with -include.
+type includeFlag []string
+
+func (f *includeFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set adds a glob pattern.
+func (f *includeFlag) Set(s string) error {
+	if _, err := filepath.Match(s, ""); err != nil {
+		return err
+	}
+	*f = append(*f, s)
+	return nil
+}
+
+// parseIncludes adds the templates in the files matching patterns to tpl,
+// so it can call them with the template action.
+func parseIncludes(tpl *template.Template, patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := tpl.ParseGlob(pattern); err != nil {
+			return fmt.Errorf("can't include %s: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// partials holds the templates include renders, so each file gets read and
+// parsed once per render, however often it's included.
+type partials struct {
+	funcs  template.FuncMap
+	parsed map[string]*template.Template
+	mu     sync.Mutex
+}
+
+func newPartials(funcs template.FuncMap) *partials {
+	return &partials{funcs: funcs, parsed: map[string]*template.Template{}}
+}
+
+// parse returns the template in the file at path, without its front matter.
+func (p *partials) parse(path string) (*template.Template, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if t, ok := p.parsed[path]; ok {
+		return t, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	_, b = splitFrontMatter(b)
+
+	t, err := template.New(path).Funcs(p.funcs).Parse(string(b))
+	if err != nil {
+		return nil, err
+	}
+	p.parsed[path] = t
+	return t, nil
+}
+
+// includeFunc returns the include template function for the template at
+// path. It renders another template file with the given data and returns the
+// result:
+//
+//	{{include "partials/repo.tpl" .}}
+//
+// Relative paths are resolved relative to the including template. stack
+// holds the templates currently being included, to detect cycles.
+func includeFunc(path string, p *partials, stack []string) func(string, interface{}) (string, error) {
+	return func(name string, data interface{}) (string, error) {
+		file := name
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(filepath.Dir(path), name)
+		}
+
+		stack := append(stack[:len(stack):len(stack)], file)
+		for _, s := range stack[:len(stack)-1] {
+			if s == file {
+				return "", fmt.Errorf("include cycle: %s", strings.Join(stack, " -> "))
+			}
+		}
+
+		t, err := p.parse(file)
+		if err != nil {
+			return "", err
+		}
+		// the includes of this one resolve against it and continue the stack
+		t, err = t.Clone()
+		if err != nil {
+			return "", err
+		}
+		t.Funcs(template.FuncMap{"include": includeFunc(file, p, stack)})
+
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+}
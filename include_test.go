@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestInclude(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+		error string
+	}{
+		{
+			name: "relative to the including template",
+			files: map[string]string{
+				"main.tpl":         `{{include "partials/a.tpl" "x"}}`,
+				"partials/a.tpl":   `a({{.}}) {{include "b.tpl" .}}`,
+				"partials/b.tpl":   `b({{.}})`,
+				"b.tpl":            `wrong b`,
+				"partials/c.tpl":   `unused`,
+				"partials/d/e.tpl": `unused`,
+			},
+			want: "a(x) b(x)",
+		},
+		{
+			name: "front matter",
+			files: map[string]string{
+				"main.tpl": `{{include "a.tpl" .}}`,
+				"a.tpl":    "---\nowner: muesli\n---\na",
+			},
+			want: "a",
+		},
+		{
+			name: "parsed once",
+			files: map[string]string{
+				"main.tpl": `{{include "a.tpl" 1}}{{remove "a.tpl"}}{{include "a.tpl" 2}}`,
+				"a.tpl":    `a{{.}}`,
+			},
+			want: "a1a2",
+		},
+		{
+			name: "same partial from different templates",
+			files: map[string]string{
+				"main.tpl": `{{include "a.tpl" .}}{{include "b.tpl" .}}`,
+				"a.tpl":    `{{include "c.tpl" "a"}}`,
+				"b.tpl":    `{{include "c.tpl" "b"}}`,
+				"c.tpl":    `c{{.}}`,
+			},
+			want: "cacb",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"main.tpl": `{{include "a.tpl" .}}`,
+				"a.tpl":    `{{include "main.tpl" .}}`,
+			},
+			error: "include cycle",
+		},
+		{
+			name: "missing",
+			files: map[string]string{
+				"main.tpl": `{{include "a.tpl" .}}`,
+			},
+			error: "no such file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				p := filepath.Join(dir, name)
+				if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil { //nolint: gosec
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(p, []byte(content), 0o644); err != nil { //nolint: gosec
+					t.Fatal(err)
+				}
+			}
+
+			tpl, err := parseJob(job{Template: filepath.Join(dir, "main.tpl")}, template.FuncMap{
+				"remove": func(name string) (string, error) {
+					return "", os.Remove(filepath.Join(dir, name))
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var b strings.Builder
+			err = tpl.Execute(&b, nil)
+			if len(tt.error) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.error) {
+					t.Fatalf("err = %v, want %q", err, tt.error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if b.String() != tt.want {
+				t.Errorf("output = %q, want %q", b.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/markscribe/scribe"
+)
+
+// lintCommand is the subcommand checking templates without rendering them.
+const lintCommand = "lint"
+
+// runLint checks the templates of jobs for mistakes and prints them to
+// stdout. It doesn't need any credentials or network access, and returns the
+// exit status.
+func runLint(jobs []job) int {
+	// without settings, every source stays offline
+	s := scribe.New(scribe.Options{})
+
+	var problems int
+	for _, j := range jobs {
+		j, err := applyFrontMatter(j)
+		if err != nil {
+			problems++
+			fmt.Println(err)
+			continue
+		}
+
+		funcMap := s.FuncMap()
+		tpl, err := parseJob(j, funcMap)
+		if err != nil {
+			problems++
+			fmt.Printf("%s: %s\n", j.Template, err)
+			continue
+		}
+		for _, p := range scribe.Lint(tpl, funcMap) {
+			problems++
+			fmt.Println(p)
+		}
+	}
+
+	if problems > 0 {
+		fmt.Fprintf(os.Stderr, "%d problems found in %d templates\n", problems, len(jobs))
+		return 1
+	}
+	fmt.Fprintf(info, "%-10s %d templates\n", "ok:", len(jobs))
+	return 0
+}
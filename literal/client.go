@@ -17,17 +17,27 @@ type Auth struct {
 
 const literalURL = "https://literal.club/graphql/"
 
-func login() (*graphql.Client, error) {
-	var auth Auth
-	if err := env.Parse(&auth); err != nil {
-		return nil, err
+// Client is a client for the literal.club API.
+type Client struct {
+	httpClient *http.Client
+	auth       Auth
+}
+
+// NewClient returns a client making its requests with httpClient, logged in
+// with auth. If httpClient is nil, http.DefaultClient is used.
+func NewClient(httpClient *http.Client, auth Auth) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
 	}
+	return &Client{httpClient: httpClient, auth: auth}
+}
 
-	client := graphql.NewClient(literalURL, http.DefaultClient)
+func (c *Client) login(ctx context.Context) (*graphql.Client, error) {
+	client := graphql.NewClient(literalURL, c.httpClient)
 	m := loginM{}
-	if err := client.Mutate(context.Background(), &m, map[string]interface{}{
-		"email":    graphql.String(auth.Email),
-		"password": graphql.String(auth.Password),
+	if err := client.Mutate(ctx, &m, map[string]interface{}{
+		"email":    graphql.String(c.auth.Email),
+		"password": graphql.String(c.auth.Password),
 	}); err != nil {
 		return nil, err
 	}
@@ -35,19 +45,30 @@ func login() (*graphql.Client, error) {
 	src := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: string(m.Login.Token)},
 	)
-	cli := oauth2.NewClient(context.Background(), src)
+	cli := oauth2.NewClient(context.WithValue(ctx, oauth2.HTTPClient, c.httpClient), src)
 	return graphql.NewClient(literalURL, cli), nil
 }
 
-// CurrentlyReading retrieves the currently reading list.
+// CurrentlyReading retrieves the currently reading list, logged in with the
+// credentials from the LITERAL_EMAIL and LITERAL_PASSWORD environment
+// variables.
 func CurrentlyReading() ([]Book, error) {
-	client, err := login()
+	var auth Auth
+	if err := env.Parse(&auth); err != nil {
+		return nil, err
+	}
+	return NewClient(nil, auth).CurrentlyReading(context.Background())
+}
+
+// CurrentlyReading retrieves the currently reading list.
+func (c *Client) CurrentlyReading(ctx context.Context) ([]Book, error) {
+	client, err := c.login(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	q := readingQ{}
-	if err := client.Query(context.Background(), &q, nil); err != nil {
+	if err := client.Query(ctx, &q, nil); err != nil {
 		return nil, err
 	}
 
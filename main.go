@@ -1,109 +1,614 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
 	"text/template"
+	"time"
 
-	"github.com/KyleBanks/goodreads"
-	"github.com/go-sprout/sprout"
-	"github.com/shurcooL/githubv4"
-	"golang.org/x/oauth2"
+	"github.com/charmbracelet/markscribe/scribe"
 )
 
 var (
-	gitHubClient    *githubv4.Client
-	goodReadsClient *goodreads.Client
-	goodReadsID     string
-	username        string
+	write      = flag.String("write", "", "write output to")
+	splice     = flag.Bool("splice", false, "only replace the marked sections of the -write target")
+	configFile = flag.String("config", "", "render the jobs described in a config file")
+	check      = flag.Bool("check", false, "don't write anything, report whether the output would change")
+	keepGoing  = flag.Bool("keep-going", false, "keep rendering when a data function fails")
+	cacheDir   = flag.String("cache-dir", "", "cache responses of data sources in this directory")
+	refresh    = flag.Bool("refresh", false, "bypass the cache, but store fresh responses in it")
+	cacheTTL   = durationFlag{}
+	record     = flag.String("record", "", "record all HTTP exchanges as fixtures in this directory")
+	replay     = flag.String("replay", "", "replay the HTTP exchanges recorded in this directory instead of using the network")
+	parallel   = flag.Int("parallel", 4, "how many data calls to make at once")
+	varsFile   = flag.String("vars", "", "read template variables from a YAML file")
+	sourceList = flag.String("sources", "", "comma-separated list of the data sources to enable (default all)")
+	checkSrcs  = flag.Bool("check-sources", false, "check that the enabled data sources are configured and reachable")
+	user       = flag.String("user", "", "GitHub user to describe (default the owner of GITHUB_TOKEN)")
+	gitHubURL  = flag.String("github-url", "", "URL of the GitHub API, e.g. of GitHub Enterprise Server (default $GITHUB_API_URL or https://api.github.com)")
+	commit     = flag.Bool("commit", false, "commit the outputs to git if they changed")
+	commitMsg  = flag.String("commit-message", defaultCommitMessage, "template of the commit message")
+	commitAuth = flag.String("commit-author", "", "author of the commit, as \"Name <email>\" (default git's user.name and user.email)")
+	push       = flag.Bool("push", false, "push the commit to origin")
+	verbose    = flag.Bool("v", false, "log every data call, and how long the calls took in the end")
+	quiet      = flag.Bool("q", false, "only report errors")
+	timeout    = flag.Duration("timeout", 0, "give up on rendering after this long, e.g. 5m")
+	srcTimeout = durationFlag{}
+	varFlags   varFlag
+	includes   includeFlag
 
-	write = flag.String("write", "", "write output to")
+	// fileVars are the variables read from -vars.
+	fileVars map[string]interface{}
+	// jobCacheTTL are the cache TTLs declared by the current job.
+	jobCacheTTL durationFlag
+	// viewer is the user set with -user, or else the one GITHUB_TOKEN
+	// belongs to.
+	viewer string
+	// sources are the enabled data sources.
+	sources []scribe.Source
+	// info receives the progress reports on stderr, unless -q is set.
+	info io.Writer = os.Stderr
 )
 
+// exitOutdated is the exit status of -check when an output would change.
+const exitOutdated = 3
+
 func main() {
+	flag.Var(cacheTTL, "cache-ttl", "how long cached responses stay valid, e.g. 30m or rss=2h (default 1h)")
+	flag.Var(srcTimeout, "source-timeout", "how long a single data call may take, e.g. 30s or rss=10s")
+	flag.Var(&varFlags, "var", "set a template variable, as key=value")
+	flag.Var(&includes, "include", "make the templates in files matching a glob pattern available")
 	flag.Parse()
+	if *quiet {
+		info = io.Discard
+	}
 
-	if len(flag.Args()) == 0 {
-		fmt.Println("Usage: markscribe [template]")
+	switch {
+	case *verbose && *quiet:
+		fmt.Fprintln(os.Stderr, "-v can't be combined with -q")
+		os.Exit(1)
+	case *commit && *check:
+		fmt.Fprintln(os.Stderr, "-commit can't be combined with -check")
+		os.Exit(1)
+	case *push && !*commit:
+		fmt.Fprintln(os.Stderr, "-push requires -commit")
 		os.Exit(1)
 	}
 
-	tplIn, err := os.ReadFile(flag.Args()[0])
-	if err != nil {
-		fmt.Println("Can't read file:", err)
+	if len(*varsFile) > 0 {
+		var err error
+		fileVars, err = loadVars(*varsFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Can't read variables:", err)
+			os.Exit(1)
+		}
+	}
+
+	if flag.Arg(0) == funcsCommand {
+		os.Exit(runFuncs(flag.Args()[1:]))
+	}
+
+	var jobs []job
+	// whether the jobs come from a config file, -config or the default one
+	var fromConfig bool
+	switch {
+	case *checkSrcs:
+		// no jobs, just the sources
+	case flag.Arg(0) == dataCommand:
+		// no jobs, just a data call
+	case flag.Arg(0) == lintCommand && flag.NArg() > 1:
+		for _, tpl := range flag.Args()[1:] {
+			jobs = append(jobs, job{Template: tpl})
+		}
+	case len(*configFile) > 0:
+		cfg, err := loadConfig(*configFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Can't read config:", err)
+			os.Exit(1)
+		}
+		jobs = cfg.Jobs
+		fromConfig = true
+	case len(flag.Args()) > 0 && flag.Arg(0) != lintCommand:
+		jobs = []job{{
+			Template: flag.Args()[0],
+			Output:   *write,
+			Splice:   *splice,
+		}}
+	default:
+		cfg, err := loadConfig(defaultConfig)
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintln(os.Stderr, "Usage: markscribe [template]\n       markscribe data <function> [args...]\n       markscribe lint [templates...]\n       markscribe funcs")
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Can't read config:", err)
+			os.Exit(1)
+		}
+		jobs = cfg.Jobs
+		fromConfig = true
+	}
+
+	if flag.Arg(0) == lintCommand {
+		os.Exit(runLint(jobs))
+	}
+
+	if len(*record) > 0 && len(*replay) > 0 {
+		fmt.Fprintln(os.Stderr, "-record and -replay can't be used together")
 		os.Exit(1)
 	}
 
-	funcMap := sprout.FuncMap(sprout.WithAlias("lower", "toLower"))
-	/* Github */
-	funcMap["recentContributions"] = recentContributions
-	funcMap["recentPullRequests"] = recentPullRequests
-	funcMap["popularRepos"] = popularRepos
-	funcMap["recentCreatedRepos"] = recentCreatedRepos
-	funcMap["recentPushedRepos"] = recentPushedRepos
-	funcMap["recentForkedRepos"] = recentForkedRepos
-	funcMap["latestReleasedRepos"] = latestReleasedRepos
-	funcMap["recentReleases"] = recentReleases
-	funcMap["followers"] = recentFollowers
-	funcMap["recentStars"] = recentStars
-	funcMap["gists"] = gists
-	funcMap["sponsors"] = sponsors
-	funcMap["repo"] = repo
-	funcMap["repoRecentReleases"] = repoRecentReleases
-	/* RSS */
-	funcMap["rss"] = rssFeed
-	/* GoodReads */
-	funcMap["goodReadsReviews"] = goodReadsReviews
-	funcMap["goodReadsCurrentlyReading"] = goodReadsCurrentlyReading
-	/* Literal.club */
-	funcMap["literalClubCurrentlyReading"] = literalClubCurrentlyReading
-	/* Utils */
-	funcMap["humanize"] = humanized
-
-	tpl, err := template.New("tpl").Funcs(funcMap).Parse(string(tplIn))
+	transport := http.DefaultTransport
+	switch {
+	case len(*record) > 0:
+		transport = &recordTransport{dir: *record, next: transport}
+	case len(*replay) > 0:
+		transport = &replayTransport{dir: *replay}
+	}
+
+	var err error
+	sources, err = configureSources(transport)
 	if err != nil {
-		fmt.Println("Can't parse template:", err)
+		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 
-	var httpClient *http.Client
+	s := scribe.New(scribe.Options{
+		Sources:   sources,
+		Timeouts:  srcTimeout,
+		KeepGoing: *keepGoing,
+		Parallel:  *parallel,
+		Logger:    newLogger(),
+	})
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, *timeout, fmt.Errorf("-timeout of %s exceeded", *timeout))
+		defer cancel()
+	}
+
+	if *checkSrcs {
+		os.Exit(checkSources(ctx, s))
+	}
+
 	gitHubToken := os.Getenv("GITHUB_TOKEN")
-	goodReadsToken := os.Getenv("GOODREADS_TOKEN")
-	goodReadsID = os.Getenv("GOODREADS_USER_ID")
-	if len(gitHubToken) > 0 {
-		httpClient = oauth2.NewClient(context.Background(), oauth2.StaticTokenSource(
-			&oauth2.Token{AccessToken: gitHubToken},
-		))
+	viewer = *user
+	if len(viewer) == 0 && sourceEnabled("github") && (len(gitHubToken) > 0 || len(*replay) > 0) {
+		var err error
+		viewer, err = s.Username(ctx)
+		// replays don't need a token, but runs recorded without one never
+		// looked up the viewer
+		if err != nil && len(gitHubToken) > 0 {
+			fmt.Fprintln(os.Stderr, "Can't retrieve GitHub profile:", err)
+			os.Exit(1)
+		}
 	}
+	s = s.WithUsername(viewer)
 
-	gitHubClient = githubv4.NewClient(httpClient)
-	goodReadsClient = goodreads.NewClient(goodReadsToken)
+	if flag.Arg(0) == dataCommand {
+		status := runData(ctx, s, flag.Args()[1:])
+		report(s)
+		os.Exit(status)
+	}
 
-	if len(gitHubToken) > 0 {
-		username, err = getUsername()
+	// a single template fails just like it always did
+	if len(jobs) == 1 && !fromConfig {
+		j, err := prepareJob(jobs[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		status, err := runJob(ctx, j, s)
+		reportFailures(os.Stderr, s)
+		report(s)
 		if err != nil {
-			fmt.Println("Can't retrieve GitHub profile:", err)
+			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
+		if status == statusOutdated {
+			os.Exit(exitOutdated)
+		}
+		if *commit && len(j.Output) > 0 {
+			if err := commitOutputs(ctx, []string{j.Output}); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+		}
+		return
 	}
 
-	w := os.Stdout
-	if len(*write) > 0 {
-		f, err := os.Create(*write)
+	var failed, outdated int
+	var outputs []string
+	for _, j := range jobs {
+		j, err := prepareJob(j)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "failed:    %s: %s\n", j, err)
+			continue
+		}
+
+		status, err := runJob(ctx, j, s)
 		if err != nil {
-			fmt.Println("Can't create:", err)
+			failed++
+			fmt.Fprintf(os.Stderr, "failed:    %s: %s\n", j, err)
+			continue
+		}
+		if status == statusOutdated {
+			outdated++
+		}
+		if len(j.Output) > 0 {
+			outputs = append(outputs, j.Output)
+		}
+		fmt.Fprintf(info, "%-10s %s\n", status+":", j)
+	}
+	reportFailures(os.Stderr, s)
+	report(s)
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d jobs failed\n", failed, len(jobs))
+		os.Exit(1)
+	}
+	if outdated > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d outputs are outdated\n", outdated, len(jobs))
+		os.Exit(exitOutdated)
+	}
+	if *commit && len(outputs) > 0 {
+		if err := commitOutputs(ctx, outputs); err != nil {
+			fmt.Fprintln(os.Stderr, err)
 			os.Exit(1)
 		}
-		defer f.Close() //nolint: errcheck
-		w = f
+	}
+}
+
+// sourceTransport returns the transport the HTTP client of source uses on
+// top of next, adding caching if enabled. Cached responses are shared by runs
+// with the same settings.
+func sourceTransport(source string, settings map[string]string, next http.RoundTripper) http.RoundTripper {
+	if len(*cacheDir) == 0 {
+		return next
+	}
+
+	names := make([]string, 0, len(settings))
+	for name := range settings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var identity strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&identity, "%s=%s\n", name, settings[name])
+	}
+
+	return newCacheTransport(filepath.Join(*cacheDir, source), identity.String(), func() time.Duration {
+		return cacheTTLFor(source)
+	}, *refresh, next)
+}
+
+// configureSources sets up the sources enabled with -sources, with their
+// settings read from the environment.
+func configureSources(transport http.RoundTripper) ([]scribe.Source, error) {
+	enabled := map[string]bool{}
+	for _, name := range strings.Split(*sourceList, ",") {
+		if name = strings.TrimSpace(name); len(name) > 0 {
+			enabled[name] = true
+		}
+	}
+
+	var sources []scribe.Source
+	for _, src := range scribe.Sources() {
+		if len(enabled) > 0 && !enabled[src.Name()] {
+			continue
+		}
+
+		settings := map[string]string{}
+		for _, s := range src.Settings() {
+			settings[s.Name] = os.Getenv(s.Env)
+		}
+		if src.Name() == "github" && len(*gitHubURL) > 0 {
+			settings["url"] = *gitHubURL
+		}
+		if err := src.Configure(scribe.SourceConfig{
+			Client:   &http.Client{Transport: sourceTransport(src.Name(), settings, transport)},
+			Settings: settings,
+		}); err != nil {
+			return nil, fmt.Errorf("can't configure %s: %w", src.Name(), err)
+		}
+		sources = append(sources, src)
 	}
 
-	err = tpl.Execute(w, nil)
+	for name := range enabled {
+		if _, ok := registeredSource(name); !ok {
+			return nil, fmt.Errorf("unknown source %q", name)
+		}
+	}
+	return sources, nil
+}
+
+// sourceEnabled reports whether the source called name is enabled.
+func sourceEnabled(name string) bool {
+	for _, src := range sources {
+		if src.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkSources checks every enabled source and returns the exit status.
+func checkSources(ctx context.Context, s *scribe.Scribe) int {
+	var failed int
+	for _, src := range sources {
+		if err := s.Check(ctx, src.Name()); err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "failed:    %s\n", err)
+			continue
+		}
+		fmt.Fprintf(info, "%-10s %s\n", "ok:", src.Name())
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d sources failed\n", failed, len(sources))
+		return 1
+	}
+	return 0
+}
+
+// cacheTTLFor returns the cache TTL for source: as set with -cache-ttl, or
+// else as declared by the current job's template.
+func cacheTTLFor(source string) time.Duration {
+	for _, ttls := range []durationFlag{cacheTTL, jobCacheTTL} {
+		if ttl, ok := ttls[source]; ok {
+			return ttl
+		}
+		if ttl, ok := ttls[""]; ok {
+			return ttl
+		}
+	}
+	return defaultCacheTTL
+}
+
+// prepareJob fills in the settings of j from its template's front matter
+// and sets up the render for it.
+func prepareJob(j job) (job, error) {
+	j, err := applyFrontMatter(j)
 	if err != nil {
-		fmt.Println("Can't render template:", err)
-		os.Exit(1)
+		return j, err
+	}
+	if j.Splice && len(j.Output) == 0 {
+		return j, fmt.Errorf("-splice requires -write")
+	}
+	// replays don't need any credentials
+	if len(*replay) == 0 {
+		if err := checkCredentials(j); err != nil {
+			return j, err
+		}
+	}
+
+	jobCacheTTL = j.cacheTTL
+
+	return j, nil
+}
+
+// jobUsername returns the GitHub user j describes: its owner, if it has
+// one, or else the viewer.
+func jobUsername(j job) string {
+	if len(j.Owner) > 0 {
+		return j.Owner
+	}
+	return viewer
+}
+
+// reportFailures writes a summary of all data calls s failed to make to w.
+func reportFailures(w io.Writer, s *scribe.Scribe) {
+	failures := s.Failures()
+	if len(failures) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "Failed data calls:")
+	for _, f := range failures {
+		fmt.Fprintf(w, "  %s: %s\n", f.Call, f.Err)
+	}
+}
+
+// report writes what the data calls of s cost to stderr: how long they took
+// with -v, and the state of the GitHub rate limit unless -q is set.
+func report(s *scribe.Scribe) {
+	if *verbose {
+		reportTimings(os.Stderr, s)
+	}
+	reportRateLimit(info, s)
+}
+
+// reportTimings writes a table of how long the data calls of s took to w.
+func reportTimings(w io.Writer, s *scribe.Scribe) {
+	timings := s.Timings()
+	if len(timings) == 0 {
+		return
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "function\tcalls\tfailed\tcached\titems\ttime")
+	for _, t := range timings {
+		d := t.Duration.Round(time.Millisecond)
+		if t.Duration < time.Millisecond {
+			d = t.Duration.Round(time.Microsecond)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\n", t.Func, t.Calls, t.Failed, t.Cached, t.Items, d)
+	}
+	tw.Flush() //nolint: errcheck
+}
+
+// newLogger returns the logger for data calls, which writes to stderr with
+// -v and discards everything otherwise.
+func newLogger() *slog.Logger {
+	w := io.Discard
+	if *verbose {
+		w = os.Stderr
+	}
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			// the time is in the duration of the calls, and CI logs have
+			// their own timestamps
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// reportRateLimit writes the cost of the GitHub queries s made, and how
+// much of the rate limit is left, to w.
+func reportRateLimit(w io.Writer, s *scribe.Scribe) {
+	limit, ok := s.GitHubRateLimit()
+	if !ok {
+		return
+	}
+
+	fmt.Fprintf(w, "GitHub API: %d queries cost %d points, %d remaining until %s\n",
+		limit.Queries, limit.Cost, limit.Remaining, limit.ResetAt.Local().Format(time.Kitchen))
+}
+
+// Job statuses reported by runJob.
+const (
+	statusWritten   = "written"
+	statusUpdated   = "updated"
+	statusUnchanged = "unchanged"
+	statusOutdated  = "outdated"
+)
+
+// runJob renders the template of j and writes it to the job's output. With
+// -check the output is left alone and a diff of what would change is printed
+// to stderr instead.
+func runJob(ctx context.Context, j job, s *scribe.Scribe) (string, error) {
+	out, err := renderJob(ctx, j, s.WithUsername(jobUsername(j)))
+	if err != nil {
+		return "", err
+	}
+
+	if len(j.Output) == 0 {
+		if *check {
+			return "", fmt.Errorf("can't check output written to stdout")
+		}
+		_, err := os.Stdout.Write(out)
+		return statusWritten, err
 	}
+
+	old, err := os.ReadFile(j.Output)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("can't read output: %w", err)
+	}
+	if bytes.Equal(old, out) {
+		return statusUnchanged, nil
+	}
+
+	if *check {
+		if err := unifiedDiff(os.Stderr, j.Output, string(old), string(out)); err != nil {
+			return "", err
+		}
+		return statusOutdated, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(j.Output), 0o755); err != nil { //nolint: gosec
+		return "", fmt.Errorf("can't write: %w", err)
+	}
+	if err := os.WriteFile(j.Output, out, 0o644); err != nil { //nolint: gosec
+		return "", fmt.Errorf("can't write: %w", err)
+	}
+	return statusUpdated, nil
+}
+
+// renderJob renders the template of j and returns the new content of its
+// output.
+func renderJob(ctx context.Context, j job, s *scribe.Scribe) ([]byte, error) {
+	tpl, err := parseJob(j, s.FuncMapContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	data, err := templateData(j)
+	if err != nil {
+		return nil, err
+	}
+	if j.Splice {
+		out, err := spliceFile(ctx, s, tpl, j.Output, data)
+		if err != nil {
+			return nil, fmt.Errorf("can't splice template: %w", err)
+		}
+		return out, nil
+	}
+	var buf bytes.Buffer
+	if err := s.Render(ctx, tpl, &buf, data); err != nil {
+		return nil, fmt.Errorf("can't render template: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// parseJob parses the template of j and the templates it includes with
+// funcMap, to which it adds the include function.
+func parseJob(j job, funcMap template.FuncMap) (*template.Template, error) {
+	tplIn, err := os.ReadFile(j.Template)
+	if err != nil {
+		return nil, fmt.Errorf("can't read file: %w", err)
+	}
+	_, tplIn = splitFrontMatter(tplIn)
+
+	// replaced below, once the template has a name to resolve paths against
+	p := newPartials(funcMap)
+	funcMap["include"] = includeFunc("", p, nil)
+
+	tpl, err := template.New(filepath.Base(j.Template)).Funcs(funcMap).Parse(string(tplIn))
+	if err != nil {
+		return nil, fmt.Errorf("can't parse template: %w", err)
+	}
+	tpl.Funcs(template.FuncMap{"include": includeFunc(j.Template, p, []string{filepath.Clean(j.Template)})})
+	if err := parseIncludes(tpl, append(j.Include, includes...)); err != nil {
+		return nil, err
+	}
+	return tpl, nil
+}
+
+// spliceFile renders the named templates of tpl into the matching marked
+// sections of the file at path and returns the result.
+func spliceFile(ctx context.Context, s *scribe.Scribe, tpl *template.Template, path string, data interface{}) ([]byte, error) {
+	doc, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	sections, err := findSections(doc)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(sections))
+	for _, sec := range sections {
+		names = append(names, sec.name)
+	}
+	tpl, err = s.Prepare(ctx, tpl, data, names...)
+	if err != nil {
+		return nil, err
+	}
+
+	return spliceSections(doc, func(name string) (string, error) {
+		t := tpl.Lookup(name)
+		if t == nil {
+			return "", fmt.Errorf("no template named %q", name)
+		}
+
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	})
 }
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var (
+	// sensitiveVariables are GraphQL variables holding credentials. They are
+	// left out when identifying requests, so fixtures can be replayed
+	// without them.
+	sensitiveVariables = []string{"email", "password", "token"}
+	// sensitiveParams are query parameters holding credentials, like the
+	// key of the GoodReads API. They are stripped from recorded URLs.
+	sensitiveParams = []string{"key", "token", "access_token", "api_key", "password"}
+	// sensitiveFields are fields of JSON responses holding credentials, like
+	// the session token of a Literal.club login.
+	sensitiveFields = []string{"token", "access_token"}
+)
+
+// redactedValue replaces the credentials in recorded responses. It isn't
+// empty, so replayed sessions still look authenticated.
+const redactedValue = "REDACTED"
+
+// fixture is a recorded HTTP exchange.
+type fixture struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
+// fixtureKey identifies a request independent of its credentials. It
+// restores the body of req after reading it.
+func fixtureKey(req *http.Request) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n", req.Method, redactURL(req.URL))
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close() //nolint: errcheck
+		if err != nil {
+			return "", err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		h.Write(redactVariables(body))
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// redactURL returns u without its sensitive query parameters.
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	for _, p := range sensitiveParams {
+		q.Del(p)
+	}
+	redacted := *u
+	redacted.RawQuery = q.Encode()
+	return redacted.String()
+}
+
+// redactResponse replaces the values of the sensitive fields of a JSON
+// response body. Any other body is returned as is.
+func redactResponse(body []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return body
+	}
+	if !redactFields(v) {
+		return body
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return body
+	}
+	return b
+}
+
+// redactFields replaces the sensitive string fields in v and everything
+// below it, and reports whether there were any.
+func redactFields(v interface{}) bool {
+	var redacted bool
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, fv := range v {
+			if _, ok := fv.(string); ok && slices.Contains(sensitiveFields, strings.ToLower(k)) {
+				v[k] = redactedValue
+				redacted = true
+				continue
+			}
+			redacted = redactFields(fv) || redacted
+		}
+	case []interface{}:
+		for _, ev := range v {
+			redacted = redactFields(ev) || redacted
+		}
+	}
+	return redacted
+}
+
+// redactVariables blanks the sensitive variables of a GraphQL request body.
+// Any other body is returned as is.
+func redactVariables(body []byte) []byte {
+	var r struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables,omitempty"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil || len(r.Query) == 0 {
+		return body
+	}
+
+	for _, v := range sensitiveVariables {
+		if _, ok := r.Variables[v]; ok {
+			r.Variables[v] = ""
+		}
+	}
+
+	b, err := json.Marshal(r)
+	if err != nil {
+		return body
+	}
+	return b
+}
+
+// recordTransport stores every exchange made through it as a fixture in dir.
+type recordTransport struct {
+	dir  string
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *recordTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := fixtureKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint: errcheck
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	b, err := json.MarshalIndent(fixture{
+		Method:     req.Method,
+		URL:        redactURL(req.URL),
+		StatusCode: resp.StatusCode,
+		Header:     resp.Header,
+		Body:       redactResponse(body),
+	}, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(t.dir, 0o700); err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, key+".json"), b, 0o600); err != nil {
+		return nil, fmt.Errorf("can't record %s %s: %w", req.Method, redactURL(req.URL), err)
+	}
+
+	return resp, nil
+}
+
+// replayTransport serves responses from the fixtures in dir and never
+// touches the network.
+type replayTransport struct {
+	dir string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *replayTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	key, err := fixtureKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(filepath.Join(t.dir, key+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("no recorded response for %s %s", req.Method, redactURL(req.URL))
+	}
+
+	var f fixture
+	if err := json.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("can't replay %s %s: %w", req.Method, redactURL(req.URL), err)
+	}
+
+	return cacheEntry{
+		StatusCode: f.StatusCode,
+		Header:     f.Header,
+		Body:       f.Body,
+	}.response(req), nil
+}
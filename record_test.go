@@ -0,0 +1,90 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordRedactsSecrets(t *testing.T) {
+	const (
+		apiKey   = "goodreads-secret-key"
+		password = "literal-secret-password"
+		session  = "literal-secret-session"
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			io.WriteString(w, `{"data":{"login":{"token":"`+session+`"}}}`) //nolint: errcheck
+			return
+		}
+		io.WriteString(w, `{"reviews":[]}`) //nolint: errcheck
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	rec := &http.Client{Transport: &recordTransport{dir: dir, next: http.DefaultTransport}}
+
+	resp, err := rec.Get(srv.URL + "/review/list?id=42&key=" + apiKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close() //nolint: errcheck
+
+	body := `{"query":"mutation{login}","variables":{"email":"me@example.com","password":"` + password + `"}}`
+	resp, err = rec.Post(srv.URL+"/graphql", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the recording run itself still gets the real token
+	b, _ := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint: errcheck
+	if !strings.Contains(string(b), session) {
+		t.Errorf("recorded response = %s, want the session token", b)
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("got %d fixtures, want 2", len(files))
+	}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, secret := range []string{apiKey, password, session} {
+			if strings.Contains(string(b), secret) {
+				t.Errorf("fixture %s contains %q", filepath.Base(f), secret)
+			}
+		}
+	}
+
+	// replays match without the credentials, or with different ones
+	replay := &http.Client{Transport: &replayTransport{dir: dir}}
+	for _, u := range []string{"/review/list?id=42", "/review/list?id=42&key=other-key"} {
+		resp, err := replay.Get(srv.URL + u)
+		if err != nil {
+			t.Errorf("replaying %s: %v", u, err)
+			continue
+		}
+		resp.Body.Close() //nolint: errcheck
+	}
+	resp, err = replay.Post(srv.URL+"/graphql", "application/json",
+		strings.NewReader(`{"query":"mutation{login}","variables":{"email":"","password":""}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ = io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint: errcheck
+	if !strings.Contains(string(b), redactedValue) {
+		t.Errorf("replayed response = %s, want the token redacted", b)
+	}
+}
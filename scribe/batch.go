@@ -0,0 +1,193 @@
+package scribe
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+const (
+	// repoBatchSize is the most repositories fetched with a single request.
+	repoBatchSize = 100
+	// maxBatchPasses limits how often a template gets executed to discover
+	// repo calls, which can depend on the results of earlier ones.
+	maxBatchPasses = 3
+)
+
+// repoKey identifies a repository.
+type repoKey struct {
+	owner, name string
+}
+
+func (k repoKey) args() []reflect.Value {
+	return []reflect.Value{reflect.ValueOf(k.owner), reflect.ValueOf(k.name)}
+}
+
+// batchRepoLookups finds the repo calls the templates called names in tpl
+// make when executed with data, e.g. in a range over popularRepos, and
+// fetches all repositories not in the memo yet with as few requests as
+// possible. The results are stored in the memo, where the repo calls of the
+// actual render pick them up.
+//
+// To find the calls, the templates are executed with a repo function that
+// records its arguments, which takes a few passes if the arguments of a
+// repo call depend on the result of another. All other data functions are
+// stubbed during these passes, see dryFuncs.
+func (g *gitHub) batchRepoLookups(ctx context.Context, tpl *template.Template, data interface{}, names ...string) {
+	if !callsRepo(tpl) {
+		return
+	}
+
+	// repositories that were looked up already, successfully or not
+	seen := map[repoKey]bool{}
+
+	for pass := 0; pass < maxBatchPasses; pass++ {
+		var missing []repoKey
+		collect := func(owner, name string) (Repo, error) {
+			k := repoKey{owner: owner, name: name}
+			if res, ok := g.s.memoLookup(g.s.callKey("repo", k.args())); ok {
+				err, _ := res[1].Interface().(error)
+				return res[0].Interface().(Repo), err
+			}
+
+			if !seen[k] {
+				seen[k] = true
+				missing = append(missing, k)
+			}
+			return Repo{}, nil
+		}
+
+		dry, err := tpl.Clone()
+		if err != nil {
+			return
+		}
+		dry.Funcs(g.s.dryFuncs(ctx))
+		dry.Funcs(template.FuncMap{"repo": collect})
+		for _, name := range names {
+			if t := dry.Lookup(name); t != nil {
+				// errors surface during the actual render
+				_ = t.Execute(io.Discard, data)
+			}
+		}
+		if len(missing) == 0 {
+			return
+		}
+
+		repos := g.fetchRepos(ctx, missing)
+		for k, r := range repos {
+			g.s.memoStore(g.s.callKey("repo", k.args()), []reflect.Value{
+				reflect.ValueOf(r),
+				reflect.Zero(errorType),
+			})
+		}
+		if len(repos) == 0 {
+			return
+		}
+	}
+}
+
+// dryFuncs returns stand-ins for the data functions of s, for executing
+// templates without fetching anything. They return the memoized result of a
+// call if there is one, and zero values otherwise, as the arguments can be
+// made up of zero values themselves. Errors are left to the actual render.
+// include, which the markscribe command adds, would render other files with
+// the real functions, so it renders nothing.
+func (s *Scribe) dryFuncs(ctx context.Context) template.FuncMap {
+	funcs := template.FuncMap{}
+	for name, fn := range s.dataFuncs(ctx) {
+		t := reflect.TypeOf(fn)
+		funcs[name] = reflect.MakeFunc(t, func(args []reflect.Value) []reflect.Value {
+			res := make([]reflect.Value, t.NumOut())
+			if memo, ok := s.memoLookup(s.callKey(name, args)); ok {
+				copy(res, memo)
+			}
+			for i := range res {
+				if !res[i].IsValid() || t.Out(i) == errorType {
+					res[i] = reflect.Zero(t.Out(i))
+				}
+			}
+			return res
+		}).Interface()
+	}
+	funcs["try"] = s.tryFunc(funcs)
+	funcs["include"] = func(string, interface{}) (string, error) {
+		return "", nil
+	}
+	return funcs
+}
+
+// callsRepo reports whether any template in tpl calls repo.
+func callsRepo(tpl *template.Template) bool {
+	var found bool
+	for _, t := range tpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		walkCommands(t.Tree.Root, func(cmd *parse.CommandNode) {
+			if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "repo" {
+				found = true
+			}
+		})
+	}
+	return found
+}
+
+// fetchRepos fetches the repositories identified by keys, using field aliases
+// to look up up to repoBatchSize repositories per request:
+//
+//	query($name0: String!, $owner0: String!, ...) {
+//	  r0: repository(owner: $owner0, name: $name0) { ... }
+//	  r1: repository(owner: $owner1, name: $name1) { ... }
+//	}
+//
+// Repositories that can't be fetched are left out, so the repo calls asking
+// for them run into the error on their own.
+func (g *gitHub) fetchRepos(ctx context.Context, keys []repoKey) map[repoKey]Repo {
+	repos := map[repoKey]Repo{}
+	nodeType := reflect.TypeOf(qlRepositoryWithRelease{})
+
+	for start := 0; start < len(keys); start += repoBatchSize {
+		batch := keys[start:min(start+repoBatchSize, len(keys))]
+
+		fields := make([]reflect.StructField, len(batch))
+		variables := map[string]interface{}{}
+		for i, k := range batch {
+			fields[i] = reflect.StructField{
+				Name: fmt.Sprintf("R%d", i),
+				Type: nodeType,
+				Tag:  reflect.StructTag(fmt.Sprintf(`graphql:"r%[1]d: repository(owner: $owner%[1]d, name: $name%[1]d)"`, i)),
+			}
+			variables[fmt.Sprintf("owner%d", i)] = githubv4.String(k.owner)
+			variables[fmt.Sprintf("name%d", i)] = githubv4.String(k.name)
+		}
+
+		query := reflect.New(reflect.StructOf(fields))
+		// a repository that doesn't exist fails the query, but the others
+		// still get decoded
+		qctx, cancel := g.s.sourceContext(ctx, g.Name())
+		stats := &callStats{}
+		qctx = context.WithValue(qctx, callStatsKey{}, stats)
+		start := time.Now()
+		err := g.query(qctx, query.Interface(), variables)
+		cancel()
+
+		found := 0
+		for i, k := range batch {
+			r := query.Elem().Field(i).Interface().(qlRepositoryWithRelease)
+			if len(r.NameWithOwner) == 0 {
+				continue
+			}
+			repos[k] = repoWithReleaseFromQL(r)
+			found++
+		}
+		g.s.traceBatch(ctx, "repo", len(batch), found, err, stats, time.Since(start))
+	}
+
+	return repos
+}
@@ -0,0 +1,74 @@
+package scribe
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+)
+
+// fakeSource is a source whose lookup function records its arguments, and
+// whose whoami function returns the user of the render.
+type fakeSource struct {
+	calls []string
+	mu    sync.Mutex
+}
+
+func (f *fakeSource) Name() string                 { return "fake" }
+func (f *fakeSource) Settings() []Setting          { return nil }
+func (f *fakeSource) Configure(SourceConfig) error { return nil }
+func (f *fakeSource) Check(context.Context) error  { return nil }
+
+func (f *fakeSource) Funcs(s *Scribe) template.FuncMap {
+	return template.FuncMap{
+		"lookup": func(_ context.Context, name string) (string, error) {
+			f.mu.Lock()
+			defer f.mu.Unlock()
+			f.calls = append(f.calls, name)
+			return strings.ToUpper(name), nil
+		},
+		"whoami": func(ctx context.Context) (string, error) {
+			return s.Username(ctx)
+		},
+	}
+}
+
+func TestBatchRepoLookupsStubsDataFuncs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"r0":{"owner":{"login":"muesli"},"name":"markscribe","nameWithOwner":"muesli/markscribe"}}}`) //nolint: errcheck
+	}))
+	defer srv.Close()
+
+	gh, err := NewSource("github", SourceConfig{
+		Client:   srv.Client(),
+		Settings: map[string]string{"url": srv.URL + "/graphql"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeSource{}
+	s := New(Options{Sources: []Source{gh, fake}, Username: "muesli", Parallel: 1})
+
+	tpl, err := s.Parse("test", `{{with repo "muesli" "markscribe"}}{{lookup .Name}}{{end}} {{try "lookup" "x"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := s.Render(context.Background(), tpl, &b, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if b.String() != "MARKSCRIBE X" {
+		t.Errorf("output = %q, want %q", b.String(), "MARKSCRIBE X")
+	}
+	// the dry passes finding the repo call don't call lookup, least of all
+	// with the empty name of a repo that wasn't fetched yet
+	if strings.Join(fake.calls, ",") != "markscribe,x" {
+		t.Errorf("lookup got called with %q, want %q", fake.calls, []string{"markscribe", "x"})
+	}
+}
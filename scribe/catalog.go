@@ -0,0 +1,183 @@
+package scribe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// FuncDoc documents a template function.
+type FuncDoc struct {
+	// Params names the parameters templates pass, in order.
+	Params []string
+	// Summary says what the function returns, in a sentence.
+	Summary string
+	// Scopes are the OAuth scopes the source's token needs for the
+	// function, if any.
+	Scopes []string
+	// Example is a template using the function.
+	Example string
+}
+
+// Documenter is implemented by sources documenting their template
+// functions.
+type Documenter interface {
+	// FuncDocs returns the documentation of the functions returned by
+	// Funcs, by name.
+	FuncDocs() map[string]FuncDoc
+}
+
+// Param is a parameter of a template function.
+type Param struct {
+	Name string
+	Type reflect.Type
+}
+
+// FuncInfo describes a template function.
+type FuncInfo struct {
+	Name string
+	// Source is the name of the source the function fetches data from, or
+	// empty for helpers.
+	Source string
+	// Params are the parameters templates pass.
+	Params []Param
+	// Variadic is set if the last parameter takes any number of arguments.
+	Variadic bool
+	// Result is the type of the function's result.
+	Result reflect.Type
+	// Credentials are the environment variables with the settings the
+	// source requires.
+	Credentials []string
+	FuncDoc
+}
+
+// Signature returns how templates call the function, like
+// "recentStars count int".
+func (f FuncInfo) Signature() string {
+	parts := []string{f.Name}
+	for i, p := range f.Params {
+		typ := TypeName(p.Type)
+		if f.Variadic && i == len(f.Params)-1 {
+			typ = "..." + TypeName(p.Type.Elem())
+		}
+		parts = append(parts, p.Name+" "+typ)
+	}
+	return strings.Join(parts, " ")
+}
+
+// helperDocs documents the template functions markscribe adds on top of the
+// data functions, other than those of sprout.
+var helperDocs = map[string]FuncDoc{
+	"humanize": {
+		Params:  []string{"value"},
+		Summary: "Formats a time relative to now, like \"3 days ago\", or anything else as a string.",
+		Example: `{{humanize .PublishedAt}}`,
+	},
+	"try": {
+		Params:  []string{"name", "args"},
+		Summary: "Calls the data function name with args, returning nil instead of failing the render.",
+		Example: `{{range try "rss" "https://domain.tld/feed.xml" 5 | default list}}{{.Title}}{{end}}`,
+	},
+}
+
+// Catalog describes the data functions of every registered source and the
+// helpers markscribe adds, sorted by source and name. The functions of
+// sprout aren't included.
+func (s *Scribe) Catalog() []FuncInfo {
+	var funcs []FuncInfo
+	for _, src := range Sources() {
+		var credentials []string
+		for _, setting := range src.Settings() {
+			if setting.Required {
+				credentials = append(credentials, setting.Env)
+			}
+		}
+		var docs map[string]FuncDoc
+		if d, ok := src.(Documenter); ok {
+			docs = d.FuncDocs()
+		}
+
+		for name, fn := range src.Funcs(s) {
+			f := funcInfo(name, fn, docs[name])
+			f.Source = src.Name()
+			f.Credentials = credentials
+			funcs = append(funcs, f)
+		}
+	}
+
+	helpers := template.FuncMap{
+		"humanize": Humanize,
+		"try":      s.tryFunc(nil),
+	}
+	for name, fn := range helpers {
+		funcs = append(funcs, funcInfo(name, fn, helperDocs[name]))
+	}
+
+	sort.Slice(funcs, func(i, j int) bool {
+		if funcs[i].Source != funcs[j].Source {
+			// helpers go last
+			if len(funcs[i].Source) == 0 || len(funcs[j].Source) == 0 {
+				return len(funcs[j].Source) == 0
+			}
+			return funcs[i].Source < funcs[j].Source
+		}
+		return funcs[i].Name < funcs[j].Name
+	})
+	return funcs
+}
+
+// funcInfo describes the template function fn called name, documented by
+// doc. The context parameter of data functions is left out, as templates
+// don't pass it.
+func funcInfo(name string, fn interface{}, doc FuncDoc) FuncInfo {
+	t := reflect.TypeOf(fn)
+	f := FuncInfo{
+		Name:     name,
+		Variadic: t.IsVariadic(),
+		Result:   t.Out(0),
+		FuncDoc:  doc,
+	}
+
+	contextType := reflect.TypeOf((*context.Context)(nil)).Elem()
+	for i := 0; i < t.NumIn(); i++ {
+		if i == 0 && t.In(i) == contextType {
+			continue
+		}
+		p := Param{Type: t.In(i)}
+		if n := len(f.Params); n < len(doc.Params) {
+			p.Name = doc.Params[n]
+		} else {
+			p.Name = fmt.Sprintf("arg%d", n+1)
+		}
+		f.Params = append(f.Params, p)
+	}
+	return f
+}
+
+// TypeName names t like Go code in this package would, e.g. "[]Repo" or
+// "time.Time".
+func TypeName(t reflect.Type) string {
+	switch {
+	case len(t.Name()) > 0 && t.PkgPath() == reflect.TypeOf(Repo{}).PkgPath():
+		return t.Name()
+	case len(t.Name()) > 0:
+		return t.String()
+	}
+
+	switch t.Kind() {
+	case reflect.Slice:
+		return "[]" + TypeName(t.Elem())
+	case reflect.Ptr:
+		return "*" + TypeName(t.Elem())
+	case reflect.Map:
+		return "map[" + TypeName(t.Key()) + "]" + TypeName(t.Elem())
+	case reflect.Interface:
+		if t.NumMethod() == 0 {
+			return "interface{}"
+		}
+	}
+	return t.String()
+}
@@ -0,0 +1,83 @@
+package scribe
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// timeout returns how long a single data call of source may take, or 0 if
+// there's no limit.
+func (s *Scribe) timeout(source string) time.Duration {
+	if d, ok := s.opts.Timeouts[source]; ok {
+		return d
+	}
+	return s.opts.Timeouts[""]
+}
+
+// sourceContext returns the context for a data call of source, limited to
+// the source's timeout.
+func (s *Scribe) sourceContext(ctx context.Context, source string) (context.Context, context.CancelFunc) {
+	if d := s.timeout(source); d > 0 {
+		return context.WithTimeout(ctx, d)
+	}
+	return context.WithCancel(ctx)
+}
+
+// bindContext returns the data function fn of source, called name, without
+// its leading context parameter, so templates can call it. Each call gets
+// ctx, limited to the source's timeout, and fails with an error naming the
+// source once that context is done. Calls get traced, see traceCall.
+func (s *Scribe) bindContext(ctx context.Context, source, name string, fn interface{}) interface{} {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.NumIn() == 0 || t.In(0) != contextType {
+		return fn
+	}
+
+	in := make([]reflect.Type, t.NumIn()-1)
+	for i := range in {
+		in[i] = t.In(i + 1)
+	}
+	out := make([]reflect.Type, t.NumOut())
+	for i := range out {
+		out[i] = t.Out(i)
+	}
+
+	ft := reflect.FuncOf(in, out, t.IsVariadic())
+	return reflect.MakeFunc(ft, func(args []reflect.Value) []reflect.Value {
+		callCtx, cancel := s.sourceContext(ctx, source)
+		defer cancel()
+		stats := &callStats{}
+		callCtx = context.WithValue(callCtx, callStatsKey{}, stats)
+		start := time.Now()
+
+		in := append([]reflect.Value{reflect.ValueOf(callCtx)}, args...)
+		var res []reflect.Value
+		if t.IsVariadic() {
+			res = v.CallSlice(in)
+		} else {
+			res = v.Call(in)
+		}
+
+		last := len(res) - 1
+		if err, ok := res[last].Interface().(error); ok && err != nil && callCtx.Err() != nil {
+			err = s.contextError(ctx, source)
+			res[last] = reflect.ValueOf(&err).Elem()
+		}
+		s.traceCall(ctx, source, name, args, res, stats, time.Since(start))
+		return res
+	}).Interface()
+}
+
+// contextError explains why a data call of source was cancelled, given the
+// context of the render it was made for.
+func (s *Scribe) contextError(ctx context.Context, source string) error {
+	if ctx.Err() != nil {
+		return fmt.Errorf("%s: %w", source, context.Cause(ctx))
+	}
+	return fmt.Errorf("%s: timed out after %s", source, s.timeout(source))
+}
@@ -0,0 +1,128 @@
+package scribe
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"text/template"
+)
+
+// Failure is a data call that failed without stopping the render, either
+// because of Options.KeepGoing or because it was called with try.
+type Failure struct {
+	// Call describes the call, e.g. rss("https://domain.tld/feed.xml", 5).
+	Call string
+	Err  error
+}
+
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// recordFailure adds a failed call to the failures, unless it's already been
+// recorded.
+func (s *Scribe) recordFailure(call string, err error) {
+	s.state.failuresMu.Lock()
+	defer s.state.failuresMu.Unlock()
+
+	for _, f := range s.state.failures {
+		if f.Call == call {
+			return
+		}
+	}
+	s.state.failures = append(s.state.failures, Failure{Call: call, Err: err})
+}
+
+// Failures returns the data calls that failed so far without stopping their
+// render.
+func (s *Scribe) Failures() []Failure {
+	s.state.failuresMu.Lock()
+	defer s.state.failuresMu.Unlock()
+	return append([]Failure(nil), s.state.failures...)
+}
+
+// continueOnError wraps the template function fn, so that instead of
+// stopping the render, errors get recorded as failures and the call returns
+// the zero value of its result.
+func (s *Scribe) continueOnError(name string, fn interface{}) interface{} {
+	v := reflect.ValueOf(fn)
+	t := v.Type()
+	if t.NumOut() != 2 || t.Out(1) != errorType {
+		return fn
+	}
+
+	return reflect.MakeFunc(t, func(args []reflect.Value) []reflect.Value {
+		res := v.Call(args)
+		if err, ok := res[1].Interface().(error); ok && err != nil {
+			s.recordFailure(formatCall(name, args), err)
+			return []reflect.Value{reflect.Zero(t.Out(0)), reflect.Zero(errorType)}
+		}
+		return res
+	}).Interface()
+}
+
+// tryFunc returns the try template function, which calls the data function
+// called name with args. If that call fails, the failure gets recorded and
+// try returns nil, so templates can fall back to a default:
+//
+//	{{range try "rss" "https://domain.tld/feed.xml" 5 | default list}}
+func (s *Scribe) tryFunc(funcs template.FuncMap) func(string, ...interface{}) (interface{}, error) {
+	return func(name string, args ...interface{}) (interface{}, error) {
+		fn, ok := funcs[name]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a data function", name)
+		}
+
+		in, err := callArgs(name, fn, args)
+		if err != nil {
+			return nil, err
+		}
+
+		res := reflect.ValueOf(fn).Call(in)
+		if err, ok := res[len(res)-1].Interface().(error); ok && err != nil {
+			s.recordFailure(formatCall(name, in), err)
+			return nil, nil
+		}
+		return res[0].Interface(), nil
+	}
+}
+
+// callArgs converts args to the parameters of the function fn called name.
+func callArgs(name string, fn interface{}, args []interface{}) ([]reflect.Value, error) {
+	t := reflect.TypeOf(fn)
+	if len(args) != t.NumIn() {
+		return nil, fmt.Errorf("wrong number of args for %s: want %d got %d", name, t.NumIn(), len(args))
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		a, err := convertArg(arg, t.In(i))
+		if err != nil {
+			return nil, fmt.Errorf("arg %d of %s: %w", i+1, name, err)
+		}
+		in[i] = a
+	}
+	return in, nil
+}
+
+// convertArg converts the template value arg to the parameter type t.
+// Strings get parsed into numbers and booleans, as they're passed on the
+// command line.
+func convertArg(arg interface{}, t reflect.Type) (reflect.Value, error) {
+	v := reflect.ValueOf(arg)
+	switch {
+	case !v.IsValid():
+		return reflect.Zero(t), nil
+	case v.Type().AssignableTo(t):
+		return v, nil
+	case v.CanInt() && t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		return v.Convert(t), nil
+	case v.Kind() == reflect.String && t.Kind() >= reflect.Int && t.Kind() <= reflect.Int64:
+		if n, err := strconv.ParseInt(v.String(), 10, t.Bits()); err == nil {
+			return reflect.ValueOf(n).Convert(t), nil
+		}
+	case v.Kind() == reflect.String && t.Kind() == reflect.Bool:
+		if b, err := strconv.ParseBool(v.String()); err == nil {
+			return reflect.ValueOf(b).Convert(t), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("can't use %v (%s) as %s", arg, v.Type(), t)
+}
@@ -0,0 +1,70 @@
+package scribe
+
+import (
+	"context"
+
+	"github.com/shurcooL/githubv4"
+)
+
+type gistsQuery struct {
+	User struct {
+		Login githubv4.String
+		Gists struct {
+			TotalCount githubv4.Int
+			Edges      []struct {
+				Cursor githubv4.String
+				Node   qlGist
+			}
+			PageInfo qlPageInfo
+		} `graphql:"gists(first: $count, after: $after, orderBy: {field: CREATED_AT, direction: DESC})"`
+	} `graphql:"user(login:$username)"`
+}
+
+func (g *gitHub) gists(ctx context.Context, count int) ([]Gist, error) {
+	login, err := g.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.gistsOf(ctx, login, count)
+}
+
+func (g *gitHub) gistsOf(ctx context.Context, login string, count int) ([]Gist, error) {
+	return paginate(count, func(first int, after *githubv4.String) ([]Gist, qlPageInfo, error) {
+		var query gistsQuery
+		variables := map[string]interface{}{
+			"username": githubv4.String(login),
+			"count":    githubv4.Int(first),
+			"after":    after,
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, qlPageInfo{}, err
+		}
+
+		var gists []Gist
+		for _, v := range query.User.Gists.Edges {
+			gists = append(gists, gistFromQL(v.Node))
+		}
+		return gists, query.User.Gists.PageInfo, nil
+	})
+}
+
+/*
+{
+  user(login: "muesli") {
+    login
+    gists(first: 100) {
+      totalCount
+      edges {
+        cursor
+        node {
+		  name
+		  description
+		  url
+		  createdAt
+        }
+      }
+    }
+  }
+}
+*/
@@ -0,0 +1,308 @@
+package scribe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+
+	"github.com/shurcooL/githubv4"
+	"golang.org/x/oauth2"
+)
+
+// gitHubAPIURL is the URL of the GitHub API, unless configured otherwise.
+const gitHubAPIURL = "https://api.github.com"
+
+func init() {
+	RegisterSource(func() Source { return &gitHub{} })
+}
+
+// gitHub is the source for the GitHub GraphQL API.
+type gitHub struct {
+	cfg     SourceConfig
+	client  *githubv4.Client
+	limiter *rateLimiter
+	// s is the Scribe the functions describe the user of, see bind.
+	s *Scribe
+}
+
+func (g *gitHub) Name() string {
+	return "github"
+}
+
+func (g *gitHub) Settings() []Setting {
+	return []Setting{
+		{Name: "token", Env: "GITHUB_TOKEN", Required: true},
+		{Name: "url", Env: "GITHUB_API_URL"},
+	}
+}
+
+// Configure sets up the GitHub client for the API at the url setting, which
+// defaults to github.com. Requests get authenticated with the token setting,
+// if it's set, and retried when they fail temporarily.
+func (g *gitHub) Configure(cfg SourceConfig) error {
+	endpoint, err := graphQLURL(cfg.Settings["url"])
+	if err != nil {
+		return err
+	}
+
+	g.cfg = cfg
+	c := *cfg.client()
+	next := c.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	c.Transport = &retryTransport{next: next}
+	if token := cfg.Settings["token"]; len(token) > 0 {
+		c.Transport = &oauth2.Transport{
+			Source: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token}),
+			Base:   c.Transport,
+		}
+	}
+
+	g.client = githubv4.NewEnterpriseClient(endpoint, &c)
+	g.limiter = &rateLimiter{}
+	return nil
+}
+
+func (g *gitHub) Funcs(s *Scribe) template.FuncMap {
+	g = g.bind(s)
+	return template.FuncMap{
+		"recentContributions": g.recentContributions,
+		"recentPullRequests":  g.recentPullRequests,
+		"recentIssues":        g.recentIssues,
+		"recentIssueComments": g.recentIssueComments,
+		"popularRepos":        g.popularRepos,
+		"recentCreatedRepos":  g.recentCreatedRepos,
+		"recentPushedRepos":   g.recentPushedRepos,
+		"recentForkedRepos":   g.recentForkedRepos,
+		"latestReleasedRepos": g.latestReleasedRepos,
+		"recentReleases":      g.recentReleases,
+		"followers":           g.recentFollowers,
+		"recentStars":         g.recentStars,
+		"gists":               g.gists,
+		"sponsors":            g.sponsors,
+		"repo":                g.repo,
+		"repoRecentReleases":  g.repoRecentReleases,
+		/* the same for any user */
+		"recentContributionsOf": g.recentContributionsOf,
+		"recentPullRequestsOf":  g.recentPullRequestsOf,
+		"recentIssuesOf":        g.recentIssuesOf,
+		"recentIssueCommentsOf": g.recentIssueCommentsOf,
+		"recentReleasesOf":      g.recentReleasesOf,
+		"followersOf":           g.recentFollowersOf,
+		"recentStarsOf":         g.recentStarsOf,
+		"gistsOf":               g.gistsOf,
+		"sponsorsOf":            g.sponsorsOf,
+	}
+}
+
+var (
+	userScopes    = []string{"repo:status", "public_repo", "read:user"}
+	orgScopes     = []string{"repo:status", "public_repo", "read:user", "read:org"}
+	ownerScopes   = []string{"repo:status", "public_repo", "read:user", "read:org (for organizations)"}
+	popularScopes = []string{"public_repo", "read:user", "read:org"}
+)
+
+func (g *gitHub) FuncDocs() map[string]FuncDoc {
+	return map[string]FuncDoc{
+		"recentContributions": {
+			Params:  []string{"count"},
+			Summary: "Repositories you recently contributed to, newest first.",
+			Scopes:  userScopes,
+			Example: `{{range recentContributions 10}}[{{.Repo.Name}}]({{.Repo.URL}}) {{humanize .OccurredAt}}{{end}}`,
+		},
+		"recentPullRequests": {
+			Params:  []string{"count"},
+			Summary: "Your recent pull requests, newest first.",
+			Scopes:  userScopes,
+			Example: `{{range recentPullRequests 10}}[{{.Title}}]({{.URL}}) on {{.Repo.Name}} is {{.State}}{{end}}`,
+		},
+		"recentIssues": {
+			Params:  []string{"count"},
+			Summary: "Issues you recently opened, newest first.",
+			Scopes:  userScopes,
+			Example: `{{range recentIssues 10}}[{{.Title}}]({{.URL}}) on {{.Repo.Name}} is {{.State}}{{end}}`,
+		},
+		"recentIssueComments": {
+			Params:  []string{"count"},
+			Summary: "Your comments on issues, most recently updated first, as GitHub doesn't order them by creation. Comments on pull requests are left out.",
+			Scopes:  userScopes,
+			Example: `{{range recentIssueComments 10}}[{{.Issue.Title}}]({{.URL}}) on {{.Issue.Repo.Name}}{{end}}`,
+		},
+		"popularRepos": {
+			Params:  []string{"owner", "count"},
+			Summary: "The repositories of owner with the most stars.",
+			Scopes:  popularScopes,
+			Example: `{{range popularRepos "charmbracelet" 10}}{{.NameWithOwner}} has {{.Stargazers}} stars{{end}}`,
+		},
+		"recentCreatedRepos": {
+			Params:  []string{"owner", "count"},
+			Summary: "The repositories owner created most recently, without forks.",
+			Scopes:  ownerScopes,
+			Example: `{{range recentCreatedRepos "charmbracelet" 10}}[{{.Name}}]({{.URL}}){{end}}`,
+		},
+		"recentPushedRepos": {
+			Params:  []string{"owner", "count"},
+			Summary: "The repositories of owner pushed to most recently.",
+			Scopes:  []string{"public_repo", "read:org"},
+			Example: `{{range recentPushedRepos "charmbracelet" 10}}[{{.Name}}]({{.URL}}) {{humanize .PushedAt}}{{end}}`,
+		},
+		"recentForkedRepos": {
+			Params:  []string{"owner", "count"},
+			Summary: "The forks owner created most recently.",
+			Scopes:  ownerScopes,
+			Example: `{{range recentForkedRepos "charmbracelet" 10}}[{{.Name}}]({{.URL}}){{end}}`,
+		},
+		"latestReleasedRepos": {
+			Params:  []string{"owner", "count"},
+			Summary: "The repositories of owner released most recently.",
+			Scopes:  orgScopes,
+			Example: `{{range latestReleasedRepos "charmbracelet" 10}}{{.Name}} {{.LastRelease.TagName}}{{end}}`,
+		},
+		"recentReleases": {
+			Params:  []string{"count"},
+			Summary: "Repositories you contributed to with the most recent releases.",
+			Scopes:  userScopes,
+			Example: `{{range recentReleases 10}}{{.Name}} [{{.LastRelease.TagName}}]({{.LastRelease.URL}}){{end}}`,
+		},
+		"followers": {
+			Params:  []string{"count"},
+			Summary: "Your latest followers.",
+			Scopes:  []string{"read:user"},
+			Example: `{{range followers 5}}[{{.Login}}]({{.URL}}){{end}}`,
+		},
+		"recentStars": {
+			Params:  []string{"count"},
+			Summary: "Repositories you recently starred, newest first.",
+			Scopes:  userScopes,
+			Example: `{{range recentStars 10}}[{{.Repo.Name}}]({{.Repo.URL}}) {{.Repo.Stargazers}}{{end}}`,
+		},
+		"gists": {
+			Params:  []string{"count"},
+			Summary: "Your published gists, newest first.",
+			Scopes:  userScopes,
+			Example: `{{range gists 10}}[{{.Description}}]({{.URL}}){{end}}`,
+		},
+		"sponsors": {
+			Params:  []string{"count"},
+			Summary: "Your sponsors, newest first.",
+			Scopes:  orgScopes,
+			Example: `{{range sponsors 5}}[{{.User.Login}}]({{.User.URL}}){{end}}`,
+		},
+		"repo": {
+			Params:  []string{"owner", "name"},
+			Summary: "The repository owner/name, with its latest release.",
+			Example: `{{with repo "charmbracelet" "markscribe"}}{{.Description}} {{.LastRelease.TagName}}{{end}}`,
+		},
+		"repoRecentReleases": {
+			Params:  []string{"owner", "name", "count"},
+			Summary: "The latest releases of the repository owner/name.",
+			Scopes:  userScopes,
+			Example: `{{range repoRecentReleases "charmbracelet" "markscribe" 10}}[{{.TagName}}]({{.URL}}){{end}}`,
+		},
+		"recentContributionsOf": {
+			Params:  []string{"login", "count"},
+			Summary: "Like recentContributions, for the user login. Organizations have no such data.",
+			Scopes:  userScopes,
+			Example: `{{range recentContributionsOf "muesli" 10}}{{.Repo.Name}}{{end}}`,
+		},
+		"recentPullRequestsOf": {
+			Params:  []string{"login", "count"},
+			Summary: "Like recentPullRequests, for the user login. Organizations have no such data.",
+			Scopes:  userScopes,
+			Example: `{{range recentPullRequestsOf "muesli" 10}}{{.Title}}{{end}}`,
+		},
+		"recentIssuesOf": {
+			Params:  []string{"login", "count"},
+			Summary: "Like recentIssues, for the user login. Organizations have no such data.",
+			Scopes:  userScopes,
+			Example: `{{range recentIssuesOf "muesli" 10}}{{.Title}}{{end}}`,
+		},
+		"recentIssueCommentsOf": {
+			Params:  []string{"login", "count"},
+			Summary: "Like recentIssueComments, for the user login: most recently updated first, without comments on pull requests. Organizations have no such data.",
+			Scopes:  userScopes,
+			Example: `{{range recentIssueCommentsOf "muesli" 10}}{{.Issue.Title}}{{end}}`,
+		},
+		"recentReleasesOf": {
+			Params:  []string{"login", "count"},
+			Summary: "Like recentReleases, for the user login. Organizations have no such data.",
+			Scopes:  userScopes,
+			Example: `{{range recentReleasesOf "muesli" 10}}{{.Name}}{{end}}`,
+		},
+		"followersOf": {
+			Params:  []string{"login", "count"},
+			Summary: "Like followers, for the user login. Organizations have no such data.",
+			Scopes:  []string{"read:user"},
+			Example: `{{range followersOf "muesli" 5}}{{.Login}}{{end}}`,
+		},
+		"recentStarsOf": {
+			Params:  []string{"login", "count"},
+			Summary: "Like recentStars, for the user login. Organizations have no such data.",
+			Scopes:  userScopes,
+			Example: `{{range recentStarsOf "muesli" 10}}{{.Repo.Name}}{{end}}`,
+		},
+		"gistsOf": {
+			Params:  []string{"login", "count"},
+			Summary: "Like gists, for the user login. Organizations have no such data.",
+			Scopes:  userScopes,
+			Example: `{{range gistsOf "muesli" 10}}{{.Name}}{{end}}`,
+		},
+		"sponsorsOf": {
+			Params:  []string{"login", "count"},
+			Summary: "Like sponsors, for the user or organization login.",
+			Scopes:  orgScopes,
+			Example: `{{range sponsorsOf "charmbracelet" 5}}{{.User.Login}}{{end}}`,
+		},
+	}
+}
+
+// Check makes sure the token is set and valid.
+func (g *gitHub) Check(ctx context.Context) error {
+	if err := missingSettings(g, g.cfg); err != nil {
+		return err
+	}
+	_, err := g.getUsername(ctx)
+	return err
+}
+
+// graphQLURL returns the GraphQL endpoint of the GitHub API at apiURL. That's
+// either the endpoint itself, the URL of the REST API as found in
+// GITHUB_API_URL on GitHub Actions, e.g. https://ghe.example.com/api/v3, or
+// just the URL of a GitHub Enterprise Server.
+func graphQLURL(apiURL string) (string, error) {
+	if len(apiURL) == 0 {
+		apiURL = gitHubAPIURL
+	}
+	u, err := url.Parse(strings.TrimSuffix(apiURL, "/"))
+	if err != nil {
+		return "", fmt.Errorf("invalid GitHub API URL: %w", err)
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || len(u.Host) == 0 {
+		return "", fmt.Errorf("invalid GitHub API URL %q: needs to start with https://", apiURL)
+	}
+
+	switch {
+	case strings.HasSuffix(u.Path, "/graphql"):
+	case strings.HasSuffix(u.Path, "/api/v3"):
+		u.Path = strings.TrimSuffix(u.Path, "/v3") + "/graphql"
+	case strings.HasSuffix(u.Path, "/api"), u.Host == "api.github.com":
+		u.Path += "/graphql"
+	default:
+		u.Path += "/api/graphql"
+	}
+	return u.String(), nil
+}
+
+// bind returns a copy of g whose functions describe the user of s.
+func (g *gitHub) bind(s *Scribe) *gitHub {
+	return &gitHub{cfg: g.cfg, client: g.client, limiter: g.limiter, s: s}
+}
+
+// login returns the GitHub user the functions describe.
+func (g *gitHub) login(ctx context.Context) (string, error) {
+	return g.s.Username(ctx)
+}
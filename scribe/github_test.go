@@ -0,0 +1,46 @@
+package scribe
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphQLURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		url   string
+		want  string
+		error string
+	}{
+		{"default", "", "https://api.github.com/graphql", ""},
+		{"github.com api", "https://api.github.com", "https://api.github.com/graphql", ""},
+		{"github.com graphql", "https://api.github.com/graphql", "https://api.github.com/graphql", ""},
+		{"enterprise host", "https://ghe.example.com", "https://ghe.example.com/api/graphql", ""},
+		{"enterprise trailing slash", "https://ghe.example.com/", "https://ghe.example.com/api/graphql", ""},
+		{"enterprise rest api", "https://ghe.example.com/api/v3", "https://ghe.example.com/api/graphql", ""},
+		{"enterprise rest api trailing slash", "https://ghe.example.com/api/v3/", "https://ghe.example.com/api/graphql", ""},
+		{"enterprise api", "https://ghe.example.com/api", "https://ghe.example.com/api/graphql", ""},
+		{"enterprise graphql", "https://ghe.example.com/api/graphql", "https://ghe.example.com/api/graphql", ""},
+		{"enterprise with port", "http://ghe.internal:8080/api/v3", "http://ghe.internal:8080/api/graphql", ""},
+		{"no scheme", "ghe.example.com", "", "needs to start with https://"},
+		{"wrong scheme", "ftp://ghe.example.com", "", "needs to start with https://"},
+		{"unparsable", "https://ghe example.com\x7f", "", "invalid GitHub API URL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := graphQLURL(tt.url)
+			if len(tt.error) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.error) {
+					t.Fatalf("err = %v, want %q", err, tt.error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("graphQLURL(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
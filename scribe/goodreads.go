@@ -0,0 +1,121 @@
+package scribe
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"text/template"
+
+	"github.com/KyleBanks/goodreads/responses"
+)
+
+const goodReadsURL = "https://www.goodreads.com"
+
+func init() {
+	RegisterSource(func() Source { return &goodReads{} })
+}
+
+// goodReads is the source for the shelves of a GoodReads user.
+type goodReads struct {
+	cfg    SourceConfig
+	client *http.Client
+}
+
+func (g *goodReads) Name() string {
+	return "goodreads"
+}
+
+func (g *goodReads) Settings() []Setting {
+	return []Setting{
+		{Name: "token", Env: "GOODREADS_TOKEN", Required: true},
+		{Name: "user_id", Env: "GOODREADS_USER_ID", Required: true},
+	}
+}
+
+func (g *goodReads) Configure(cfg SourceConfig) error {
+	g.cfg = cfg
+	g.client = cfg.client()
+	return nil
+}
+
+func (g *goodReads) Funcs(*Scribe) template.FuncMap {
+	return template.FuncMap{
+		"goodReadsReviews":          g.reviews,
+		"goodReadsCurrentlyReading": g.currentlyReading,
+	}
+}
+
+func (g *goodReads) FuncDocs() map[string]FuncDoc {
+	return map[string]FuncDoc{
+		"goodReadsReviews": {
+			Params:  []string{"count"},
+			Summary: "Your latest GoodReads reviews.",
+			Example: `{{range goodReadsReviews 5}}{{.Book.Title}} {{.Rating}}{{end}}`,
+		},
+		"goodReadsCurrentlyReading": {
+			Params:  []string{"count"},
+			Summary: "The books on your GoodReads currently-reading shelf.",
+			Example: `{{range goodReadsCurrentlyReading 5}}{{.Book.Title}}{{end}}`,
+		},
+	}
+}
+
+// Check makes sure the settings are set and fetches a review with them.
+func (g *goodReads) Check(ctx context.Context) error {
+	if err := missingSettings(g, g.cfg); err != nil {
+		return err
+	}
+	_, err := g.reviewList(ctx, "read", "date_read", 1)
+	return err
+}
+
+func (g *goodReads) reviews(ctx context.Context, count int) ([]responses.Review, error) {
+	return g.reviewList(ctx, "read", "date_read", count)
+}
+
+func (g *goodReads) currentlyReading(ctx context.Context, count int) ([]responses.Review, error) {
+	return g.reviewList(ctx, "currently-reading", "date_updated", count)
+}
+
+// reviewList returns the first count books on a shelf of the user, newest
+// first. See https://www.goodreads.com/api/index#reviews.list.
+func (g *goodReads) reviewList(ctx context.Context, shelf, sort string, count int) ([]responses.Review, error) {
+	v := url.Values{}
+	v.Set("key", g.cfg.Settings["token"])
+	v.Set("v", "2")
+	v.Set("shelf", shelf)
+	v.Set("sort", sort)
+	v.Set("order", "d")
+	v.Set("page", "1")
+	v.Set("per_page", strconv.Itoa(count))
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/review/list/%s.xml?%s", goodReadsURL, url.PathEscape(g.cfg.Settings["user_id"]), v.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint: errcheck
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response code: %d", resp.StatusCode)
+	}
+
+	var r struct {
+		Reviews []responses.Review `xml:"reviews>review"`
+	}
+	if err := xml.Unmarshal(body, &r); err != nil {
+		return nil, err
+	}
+	return r.Reviews, nil
+}
@@ -0,0 +1,150 @@
+package scribe
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shurcooL/githubv4"
+)
+
+type recentIssuesQuery struct {
+	User struct {
+		Login  githubv4.String
+		Issues struct {
+			TotalCount githubv4.Int
+			Edges      []struct {
+				Cursor githubv4.String
+				Node   qlIssue
+			}
+			PageInfo qlPageInfo
+		} `graphql:"issues(first: $count, after: $after, orderBy: {field: CREATED_AT, direction: DESC})"`
+	} `graphql:"user(login:$username)"`
+}
+
+type recentIssueCommentsQuery struct {
+	User struct {
+		Login         githubv4.String
+		IssueComments struct {
+			TotalCount githubv4.Int
+			Edges      []struct {
+				Cursor githubv4.String
+				Node   struct {
+					qlIssueComment
+					// set for comments on pull requests
+					PullRequest *struct {
+						URL githubv4.String
+					}
+				}
+			}
+			PageInfo qlPageInfo
+		} `graphql:"issueComments(first: $count, after: $after, orderBy: {field: UPDATED_AT, direction: DESC})"`
+	} `graphql:"user(login:$username)"`
+}
+
+func (g *gitHub) recentIssues(ctx context.Context, count int) ([]Issue, error) {
+	login, err := g.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.recentIssuesOf(ctx, login, count)
+}
+
+func (g *gitHub) recentIssuesOf(ctx context.Context, login string, count int) ([]Issue, error) {
+	return paginate(count, func(first int, after *githubv4.String) ([]Issue, qlPageInfo, error) {
+		var query recentIssuesQuery
+		variables := map[string]interface{}{
+			"username": githubv4.String(login),
+			"count":    githubv4.Int(first),
+			"after":    after,
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, qlPageInfo{}, err
+		}
+
+		var issues []Issue
+		for _, v := range query.User.Issues.Edges {
+			// ignore meta-repo
+			if string(v.Node.Repository.NameWithOwner) == fmt.Sprintf("%s/%s", login, login) {
+				continue
+			}
+			if v.Node.Repository.IsPrivate {
+				continue
+			}
+
+			issues = append(issues, issueFromQL(v.Node))
+		}
+		return issues, query.User.Issues.PageInfo, nil
+	})
+}
+
+// recentIssueComments returns the comments the user wrote on issues, most
+// recently updated first, as GitHub doesn't order them by creation.
+// Comments on pull requests are left out.
+func (g *gitHub) recentIssueComments(ctx context.Context, count int) ([]IssueComment, error) {
+	login, err := g.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.recentIssueCommentsOf(ctx, login, count)
+}
+
+func (g *gitHub) recentIssueCommentsOf(ctx context.Context, login string, count int) ([]IssueComment, error) {
+	return paginate(count, func(first int, after *githubv4.String) ([]IssueComment, qlPageInfo, error) {
+		var query recentIssueCommentsQuery
+		variables := map[string]interface{}{
+			"username": githubv4.String(login),
+			"count":    githubv4.Int(first),
+			"after":    after,
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, qlPageInfo{}, err
+		}
+
+		var comments []IssueComment
+		for _, v := range query.User.IssueComments.Edges {
+			if v.Node.PullRequest != nil {
+				continue
+			}
+			// ignore meta-repo
+			if string(v.Node.Issue.Repository.NameWithOwner) == fmt.Sprintf("%s/%s", login, login) {
+				continue
+			}
+			if v.Node.Issue.Repository.IsPrivate {
+				continue
+			}
+
+			comments = append(comments, issueCommentFromQL(v.Node.qlIssueComment))
+		}
+		return comments, query.User.IssueComments.PageInfo, nil
+	})
+}
+
+/*
+{
+	viewer {
+		issues(first: 3, orderBy: {field: CREATED_AT, direction: DESC}) {
+			edges {
+				node {
+					title
+					url
+					state
+					createdAt
+					labels(first: 10) {
+						nodes {
+							name
+						}
+					}
+					comments {
+						totalCount
+					}
+					repository {
+						nameWithOwner
+					}
+				}
+			}
+		}
+	}
+}
+*/
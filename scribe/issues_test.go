@@ -0,0 +1,82 @@
+package scribe
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// issueNode returns a GraphQL issue titled title in the repository repo.
+func issueNode(title, repo string, private bool) string {
+	return `{"title":"` + title + `","url":"https://github.com/` + repo + `/issues/1","state":"OPEN",` +
+		`"repository":{"owner":{"login":"` + strings.Split(repo, "/")[0] + `"},"nameWithOwner":"` + repo + `","isPrivate":` + strconv.FormatBool(private) + `}}`
+}
+
+func TestIssuesFiltering(t *testing.T) {
+	issues := `{"data":{"user":{"login":"muesli","issues":{"edges":[` +
+		`{"node":` + issueNode("meta", "muesli/muesli", false) + `},` +
+		`{"node":` + issueNode("private", "muesli/secret", true) + `},` +
+		`{"node":` + issueNode("public", "charmbracelet/glow", false) + `}` +
+		`],"pageInfo":{"hasNextPage":false}}}}}`
+	comments := `{"data":{"user":{"login":"muesli","issueComments":{"edges":[` +
+		`{"node":{"body":"on meta","issue":` + issueNode("meta", "muesli/muesli", false) + `}},` +
+		`{"node":{"body":"on private","issue":` + issueNode("private", "muesli/secret", true) + `}},` +
+		`{"node":{"body":"on pull request","issue":` + issueNode("pr", "charmbracelet/glow", false) + `,"pullRequest":{"url":"https://github.com/charmbracelet/glow/pull/2"}}},` +
+		`{"node":{"body":"edited lately","issue":` + issueNode("public", "charmbracelet/glow", false) + `}},` +
+		`{"node":{"body":"edited earlier","issue":` + issueNode("public", "charmbracelet/glow", false) + `}}` +
+		`],"pageInfo":{"hasNextPage":false}}}}}`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(string(b), "issueComments"):
+			if !strings.Contains(string(b), "field: UPDATED_AT") {
+				t.Errorf("comments aren't ordered by update: %s", b)
+			}
+			io.WriteString(w, comments) //nolint: errcheck
+		default:
+			io.WriteString(w, issues) //nolint: errcheck
+		}
+	}))
+	defer srv.Close()
+
+	gh, err := NewSource("github", SourceConfig{
+		Client:   srv.Client(),
+		Settings: map[string]string{"url": srv.URL + "/graphql"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(Options{Sources: []Source{gh}, Username: "muesli"})
+
+	// the meta-repo, private repositories and pull requests are left out
+	issuesOut, err := s.Call(context.Background(), "recentIssues", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, issue := range issuesOut.([]Issue) {
+		titles = append(titles, issue.Title)
+	}
+	if want := []string{"public"}; !slices.Equal(titles, want) {
+		t.Errorf("issues = %q, want %q", titles, want)
+	}
+
+	commentsOut, err := s.Call(context.Background(), "recentIssueCommentsOf", "muesli", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bodies []string
+	for _, c := range commentsOut.([]IssueComment) {
+		bodies = append(bodies, c.Body)
+	}
+	if want := []string{"edited lately", "edited earlier"}; !slices.Equal(bodies, want) {
+		t.Errorf("comments = %q, want %q", bodies, want)
+	}
+}
@@ -0,0 +1,403 @@
+package scribe
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Problem is a mistake in a template, found by Lint.
+type Problem struct {
+	// Pos is where the problem is, as template:line:column.
+	Pos string
+	Msg string
+}
+
+func (p Problem) String() string {
+	return p.Pos + ": " + p.Msg
+}
+
+// Lint checks the templates associated with tpl, which were parsed with
+// funcs, without executing them: whether functions get called with the
+// right number of arguments, whether constant arguments have the right
+// types, and whether the fields accessed on results, like .Repo.Name on the
+// items of recentStars, exist. Whatever can't be known before rendering, like
+// the fields of template variables, isn't checked.
+func Lint(tpl *template.Template, funcs template.FuncMap) []Problem {
+	l := &linter{
+		tpl:     tpl,
+		funcs:   map[string]reflect.Type{},
+		checked: map[string]bool{},
+		seen:    map[Problem]bool{},
+	}
+	for name, fn := range funcs {
+		l.funcs[name] = reflect.TypeOf(fn)
+	}
+
+	// tpl first, then the templates associated with it in a stable order, so
+	// problems get reported in the same order every time
+	templates := tpl.Templates()
+	slices.SortFunc(templates, func(a, b *template.Template) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	l.lintTemplate(tpl.Name(), nil)
+	for _, t := range templates {
+		l.lintTemplate(t.Name(), nil)
+	}
+	return l.problems
+}
+
+// linter keeps track of the types values have while walking templates. A nil
+// type is unknown until the template gets executed.
+type linter struct {
+	tpl   *template.Template
+	funcs map[string]reflect.Type
+	// checked holds the templates checked already, by name and the type of
+	// their dot
+	checked  map[string]bool
+	problems []Problem
+	seen     map[Problem]bool
+}
+
+// vars are the types of the variables in scope, by name including the $.
+type vars map[string]reflect.Type
+
+func (v vars) copy() vars {
+	c := make(vars, len(v))
+	for k, t := range v {
+		c[k] = t
+	}
+	return c
+}
+
+var (
+	boolType   = reflect.TypeOf(false)
+	intType    = reflect.TypeOf(0)
+	floatType  = reflect.TypeOf(0.0)
+	stringType = reflect.TypeOf("")
+)
+
+// report adds a problem found at node of tree.
+func (l *linter) report(tree *parse.Tree, node parse.Node, format string, args ...interface{}) {
+	loc, _ := tree.ErrorContext(node)
+	p := Problem{Pos: loc, Msg: fmt.Sprintf(format, args...)}
+	if l.seen[p] {
+		return
+	}
+	l.seen[p] = true
+	l.problems = append(l.problems, p)
+}
+
+// lintTemplate checks the template called name, executed with a dot of type
+// dot.
+func (l *linter) lintTemplate(name string, dot reflect.Type) {
+	key := fmt.Sprintf("%s\x00%v", name, dot)
+	if l.checked[key] {
+		return
+	}
+	l.checked[key] = true
+
+	t := l.tpl.Lookup(name)
+	if t == nil || t.Tree == nil || t.Tree.Root == nil {
+		return
+	}
+	l.walk(t.Tree, t.Tree.Root, dot, vars{"$": dot})
+}
+
+// walk checks node of tree and everything below it.
+func (l *linter) walk(tree *parse.Tree, node parse.Node, dot reflect.Type, vs vars) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		for _, c := range n.Nodes {
+			l.walk(tree, c, dot, vs)
+		}
+
+	case *parse.ActionNode:
+		l.pipe(tree, n.Pipe, dot, vs)
+
+	case *parse.IfNode:
+		inner := vs.copy()
+		l.pipe(tree, n.Pipe, dot, inner)
+		l.walk(tree, n.List, dot, inner)
+		if n.ElseList != nil {
+			l.walk(tree, n.ElseList, dot, vs.copy())
+		}
+
+	case *parse.WithNode:
+		inner := vs.copy()
+		t := l.pipe(tree, n.Pipe, dot, inner)
+		l.walk(tree, n.List, t, inner)
+		if n.ElseList != nil {
+			l.walk(tree, n.ElseList, dot, vs.copy())
+		}
+
+	case *parse.RangeNode:
+		inner := vs.copy()
+		t := l.cmds(tree, n.Pipe, dot, inner)
+		key, elem := l.rangeTypes(tree, n, t)
+		switch len(n.Pipe.Decl) {
+		case 1:
+			inner[n.Pipe.Decl[0].Ident[0]] = elem
+		case 2:
+			inner[n.Pipe.Decl[0].Ident[0]] = key
+			inner[n.Pipe.Decl[1].Ident[0]] = elem
+		}
+		l.walk(tree, n.List, elem, inner)
+		if n.ElseList != nil {
+			l.walk(tree, n.ElseList, dot, vs.copy())
+		}
+
+	case *parse.TemplateNode:
+		var t reflect.Type
+		if n.Pipe != nil {
+			t = l.pipe(tree, n.Pipe, dot, vs.copy())
+		}
+		if l.tpl.Lookup(n.Name) == nil {
+			l.report(tree, n, "no such template %q", n.Name)
+			return
+		}
+		l.lintTemplate(n.Name, t)
+	}
+}
+
+// pipe checks pipe, declares its variables and returns the type of its
+// result.
+func (l *linter) pipe(tree *parse.Tree, pipe *parse.PipeNode, dot reflect.Type, vs vars) reflect.Type {
+	t := l.cmds(tree, pipe, dot, vs)
+	for _, v := range pipe.Decl {
+		vs[v.Ident[0]] = t
+	}
+	return t
+}
+
+// cmds checks the commands of pipe and returns the type of its result.
+func (l *linter) cmds(tree *parse.Tree, pipe *parse.PipeNode, dot reflect.Type, vs vars) reflect.Type {
+	var t reflect.Type
+	for i, cmd := range pipe.Cmds {
+		t = l.cmd(tree, cmd, dot, vs, t, i > 0)
+	}
+	return t
+}
+
+// cmd checks cmd, which gets the result of the previous command of its pipe
+// as its last argument if piped is set, and returns the type of its result.
+func (l *linter) cmd(tree *parse.Tree, cmd *parse.CommandNode, dot reflect.Type, vs vars, prev reflect.Type, piped bool) reflect.Type {
+	if id, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		return l.call(tree, cmd, id.Ident, cmd.Args[1:], dot, vs, prev, piped)
+	}
+	for _, arg := range cmd.Args[1:] {
+		l.arg(tree, arg, dot, vs)
+	}
+	// fields and methods called with arguments aren't checked any further
+	return l.arg(tree, cmd.Args[0], dot, vs)
+}
+
+// call checks the call of the function name with args, plus the result of
+// the previous command if piped is set, and returns the type of its result.
+func (l *linter) call(tree *parse.Tree, cmd *parse.CommandNode, name string, args []parse.Node, dot reflect.Type, vs vars, prev reflect.Type, piped bool) reflect.Type {
+	types := make([]reflect.Type, len(args))
+	for i, arg := range args {
+		types[i] = l.arg(tree, arg, dot, vs)
+	}
+
+	switch name {
+	case "len":
+		return intType
+	case "and", "or", "index", "slice", "call":
+		return nil
+	case "not", "eq", "ne", "lt", "le", "gt", "ge":
+		return boolType
+	case "print", "printf", "println", "html", "js", "urlquery":
+		return stringType
+	}
+
+	ft, ok := l.funcs[name]
+	if !ok {
+		// parsing fails on unknown functions, so this is a builtin
+		return nil
+	}
+
+	n := len(args)
+	if piped {
+		n++
+	}
+	if ft.IsVariadic() && n < ft.NumIn()-1 {
+		l.report(tree, cmd, "wrong number of args for %s: want at least %d got %d", name, ft.NumIn()-1, n)
+	} else if !ft.IsVariadic() && n != ft.NumIn() {
+		l.report(tree, cmd, "wrong number of args for %s: want %d got %d", name, ft.NumIn(), n)
+	} else {
+		for i, arg := range args {
+			l.checkArg(tree, name, i, arg, types[i], paramType(ft, i))
+		}
+		if piped {
+			l.checkArg(tree, name, n-1, cmd, prev, paramType(ft, n-1))
+		}
+	}
+
+	if ft.NumOut() == 0 {
+		return nil
+	}
+	return ft.Out(0)
+}
+
+// paramType returns the type of the i-th argument of a function of type ft.
+func paramType(ft reflect.Type, i int) reflect.Type {
+	if ft.IsVariadic() && i >= ft.NumIn()-1 {
+		return ft.In(ft.NumIn() - 1).Elem()
+	}
+	return ft.In(i)
+}
+
+// checkArg reports if arg, the i-th argument of a call of the function name,
+// can't be passed as a parameter of type param. t is the type of arg, if
+// known.
+func (l *linter) checkArg(tree *parse.Tree, name string, i int, arg parse.Node, t, param reflect.Type) {
+	if param.Kind() == reflect.Interface {
+		return
+	}
+
+	var ok bool
+	switch a := arg.(type) {
+	case *parse.NumberNode:
+		switch {
+		case param.Kind() >= reflect.Int && param.Kind() <= reflect.Uint64:
+			ok = a.IsInt || a.IsUint
+		case param.Kind() == reflect.Float32 || param.Kind() == reflect.Float64:
+			ok = a.IsFloat
+		}
+	case *parse.StringNode:
+		ok = param.Kind() == reflect.String
+	case *parse.BoolNode:
+		ok = param.Kind() == reflect.Bool
+	case *parse.NilNode:
+		ok = param.Kind() == reflect.Ptr || param.Kind() == reflect.Slice ||
+			param.Kind() == reflect.Map || param.Kind() == reflect.Func || param.Kind() == reflect.Chan
+	default:
+		ok = t == nil || t.Kind() == reflect.Interface || t.AssignableTo(param)
+	}
+	if !ok {
+		l.report(tree, arg, "arg %d of %s: can't use %s as %s", i+1, name, describe(arg, t), param)
+	}
+}
+
+// describe describes the argument arg of type t for reports.
+func describe(arg parse.Node, t reflect.Type) string {
+	switch arg.(type) {
+	case *parse.NumberNode, *parse.StringNode, *parse.BoolNode, *parse.NilNode:
+		return arg.String()
+	case *parse.CommandNode:
+		return fmt.Sprintf("the piped %s", t)
+	}
+	return fmt.Sprintf("%s (%s)", arg, t)
+}
+
+// arg checks the argument arg and returns its type.
+func (l *linter) arg(tree *parse.Tree, arg parse.Node, dot reflect.Type, vs vars) reflect.Type {
+	switch a := arg.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return l.fields(tree, a, dot, a.Ident)
+	case *parse.VariableNode:
+		t, ok := vs[a.Ident[0]]
+		if !ok {
+			// parsing fails on undefined variables, so this is unknown
+			return nil
+		}
+		return l.fields(tree, a, t, a.Ident[1:])
+	case *parse.ChainNode:
+		return l.fields(tree, a, l.arg(tree, a.Node, dot, vs), a.Field)
+	case *parse.PipeNode:
+		return l.pipe(tree, a, dot, vs)
+	case *parse.IdentifierNode:
+		return l.call(tree, &parse.CommandNode{NodeType: parse.NodeCommand, Pos: a.Pos, Args: []parse.Node{a}}, a.Ident, nil, dot, vs, nil, false)
+	case *parse.StringNode:
+		return stringType
+	case *parse.BoolNode:
+		return boolType
+	case *parse.NumberNode:
+		if a.IsInt {
+			return intType
+		}
+		return floatType
+	}
+	return nil
+}
+
+// fields resolves the chain of field or method names on a value of type t
+// and returns the type of the result.
+func (l *linter) fields(tree *parse.Tree, node parse.Node, t reflect.Type, names []string) reflect.Type {
+	for _, name := range names {
+		if t == nil {
+			return nil
+		}
+
+		m, ok := t.MethodByName(name)
+		if !ok && t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface {
+			m, ok = reflect.PointerTo(t).MethodByName(name)
+		}
+		if ok {
+			if m.Type.NumOut() == 0 {
+				return nil
+			}
+			t = m.Type.Out(0)
+			continue
+		}
+
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		switch t.Kind() {
+		case reflect.Struct:
+			f, ok := t.FieldByName(name)
+			if !ok || !f.IsExported() {
+				l.report(tree, node, "can't evaluate field %s in type %s", name, t)
+				return nil
+			}
+			t = f.Type
+		case reflect.Map:
+			if t.Key().Kind() != reflect.String {
+				return nil
+			}
+			t = t.Elem()
+		case reflect.Interface:
+			return nil
+		default:
+			l.report(tree, node, "can't evaluate field %s in type %s", name, t)
+			return nil
+		}
+		if t.Kind() == reflect.Interface {
+			return nil
+		}
+	}
+	return t
+}
+
+// rangeTypes returns the types of the keys and elements n ranges over, a
+// value of type t.
+func (l *linter) rangeTypes(tree *parse.Tree, n *parse.RangeNode, t reflect.Type) (reflect.Type, reflect.Type) {
+	if t == nil {
+		return nil, nil
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return intType, t.Elem()
+	case reflect.Map:
+		return t.Key(), t.Elem()
+	case reflect.Chan:
+		return nil, t.Elem()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return t, t
+	case reflect.Interface, reflect.Func:
+		return nil, nil
+	}
+	l.report(tree, n, "range can't iterate over %s", t)
+	return nil, nil
+}
@@ -0,0 +1,155 @@
+package scribe
+
+import (
+	"slices"
+	"testing"
+	"text/template"
+)
+
+type lintRepo struct {
+	Name  string
+	Stars int
+}
+
+type lintStar struct {
+	Repo lintRepo
+}
+
+var lintFuncs = template.FuncMap{
+	"stars": func(int) ([]lintStar, error) { return nil, nil },
+	"repo":  func(string, string) (lintRepo, error) { return lintRepo{}, nil },
+	"join":  func(string, ...string) string { return "" },
+	"ratio": func(float64) string { return "" },
+	"any":   func(interface{}) string { return "" },
+}
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name string
+		tpl  string
+		want []string
+	}{
+		{
+			name: "fine",
+			tpl:  `{{range stars 5}}{{.Repo.Name}} {{.Repo.Stars}}{{end}}{{with repo "a" "b"}}{{.Name}}{{end}}`,
+		},
+		{
+			name: "too few args",
+			tpl:  `{{stars}}`,
+			want: []string{"main:1:2: wrong number of args for stars: want 1 got 0"},
+		},
+		{
+			name: "too many args",
+			tpl:  "\n{{repo \"a\" \"b\" \"c\"}}",
+			want: []string{"main:2:2: wrong number of args for repo: want 2 got 3"},
+		},
+		{
+			name: "piped args",
+			tpl:  `{{5 | stars}}{{"b" | repo "a"}}{{"b" | repo "a" "c"}}`,
+			want: []string{"main:1:39: wrong number of args for repo: want 2 got 3"},
+		},
+		{
+			name: "variadic args",
+			tpl:  `{{join ", " "a" "b"}}{{join ", "}}{{join}}`,
+			want: []string{"main:1:36: wrong number of args for join: want at least 1 got 0"},
+		},
+		{
+			name: "constant arg types",
+			tpl:  `{{stars "5"}}{{repo "a" 5}}{{stars 1.5}}{{ratio 1.5}}{{ratio 2}}{{any 5}}{{repo true nil}}`,
+			want: []string{
+				`main:1:8: arg 1 of stars: can't use "5" as int`,
+				"main:1:24: arg 2 of repo: can't use 5 as string",
+				"main:1:35: arg 1 of stars: can't use 1.5 as int",
+				"main:1:80: arg 1 of repo: can't use true as string",
+				"main:1:85: arg 2 of repo: can't use nil as string",
+			},
+		},
+		{
+			name: "result arg types",
+			tpl:  `{{stars (repo "a" "b").Stars}}{{stars (repo "a" "b").Name}}{{repo "a" "b" | stars}}`,
+			want: []string{
+				"main:1:52: arg 1 of stars: can't use (repo \"a\" \"b\").Name (string) as int",
+				"main:1:76: arg 1 of stars: can't use the piped scribe.lintRepo as int",
+			},
+		},
+		{
+			name: "fields through range",
+			tpl:  `{{range stars 5}}{{.Repo.Nam}}{{end}}`,
+			want: []string{"main:1:24: can't evaluate field Nam in type scribe.lintRepo"},
+		},
+		{
+			name: "fields through with",
+			tpl:  `{{with repo "a" "b"}}{{.Stars}}{{.Star}}{{end}}`,
+			want: []string{"main:1:33: can't evaluate field Star in type scribe.lintRepo"},
+		},
+		{
+			name: "fields through variables",
+			tpl:  `{{$r := repo "a" "b"}}{{$r.Name}}{{$r.Nam}}{{range $i, $s := stars 5}}{{$i}}{{$s.Rep}}{{end}}`,
+			want: []string{
+				"main:1:37: can't evaluate field Nam in type scribe.lintRepo",
+				"main:1:80: can't evaluate field Rep in type scribe.lintStar",
+			},
+		},
+		{
+			name: "fields of scalars",
+			tpl:  `{{(repo "a" "b").Name.Length}}`,
+			want: []string{"main:1:16: can't evaluate field Length in type string"},
+		},
+		{
+			name: "range over a struct",
+			tpl:  `{{range repo "a" "b"}}{{end}}`,
+			want: []string{"main:1:8: range can't iterate over scribe.lintRepo"},
+		},
+		{
+			name: "unknown data",
+			tpl:  `{{.Anything.Goes}}{{range .items}}{{.Name}}{{end}}{{$.What}}`,
+		},
+		{
+			name: "template calls",
+			tpl:  `{{define "item"}}{{.Name}}{{.Nam}}{{end}}{{range stars 5}}{{template "item" .Repo}}{{end}}{{template "item" .}}`,
+			want: []string{"main:1:28: can't evaluate field Nam in type scribe.lintRepo"},
+		},
+		{
+			name: "missing template",
+			tpl:  `{{template "nope" .}}`,
+			want: []string{`main:1:11: no such template "nope"`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl, err := template.New("main").Funcs(lintFuncs).Parse(tt.tpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, p := range Lint(tpl, lintFuncs) {
+				got = append(got, p.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Lint() =\n%q\nwant\n%q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLintOrder(t *testing.T) {
+	tpl, err := template.New("main").Funcs(lintFuncs).Parse("{{stars}}\n" +
+		"{{define \"d\"}}{{stars}}{{end}}\n{{define \"b\"}}{{stars}}{{end}}\n" +
+		"{{define \"c\"}}{{stars}}{{end}}\n{{define \"a\"}}{{stars}}{{end}}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// main first, then a, b, c and d
+	want := []string{"main:1:2", "main:5:16", "main:3:16", "main:4:16", "main:2:16"}
+	for i := 0; i < 10; i++ {
+		var got []string
+		for _, p := range Lint(tpl, lintFuncs) {
+			got = append(got, p.Pos)
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("problems at %q, want %q", got, want)
+		}
+	}
+}
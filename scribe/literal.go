@@ -0,0 +1,74 @@
+package scribe
+
+import (
+	"context"
+	"text/template"
+
+	"github.com/charmbracelet/markscribe/literal"
+)
+
+func init() {
+	RegisterSource(func() Source { return &literalClub{} })
+}
+
+// literalClub is the source for the reading list of a literal.club user.
+type literalClub struct {
+	cfg    SourceConfig
+	client *literal.Client
+}
+
+func (l *literalClub) Name() string {
+	return "literal"
+}
+
+func (l *literalClub) Settings() []Setting {
+	return []Setting{
+		{Name: "email", Env: "LITERAL_EMAIL", Required: true},
+		{Name: "password", Env: "LITERAL_PASSWORD", Required: true},
+	}
+}
+
+func (l *literalClub) Configure(cfg SourceConfig) error {
+	l.cfg = cfg
+	l.client = literal.NewClient(cfg.client(), literal.Auth{
+		Email:    cfg.Settings["email"],
+		Password: cfg.Settings["password"],
+	})
+	return nil
+}
+
+func (l *literalClub) Funcs(*Scribe) template.FuncMap {
+	return template.FuncMap{
+		"literalClubCurrentlyReading": l.currentlyReading,
+	}
+}
+
+func (l *literalClub) FuncDocs() map[string]FuncDoc {
+	return map[string]FuncDoc{
+		"literalClubCurrentlyReading": {
+			Params:  []string{"count"},
+			Summary: "The books you're currently reading on Literal.club.",
+			Example: `{{range literalClubCurrentlyReading 5}}{{.Title}}{{range .Authors}} {{.Name}}{{end}}{{end}}`,
+		},
+	}
+}
+
+// Check makes sure the credentials are set and logs in with them.
+func (l *literalClub) Check(ctx context.Context) error {
+	if err := missingSettings(l, l.cfg); err != nil {
+		return err
+	}
+	_, err := l.client.CurrentlyReading(ctx)
+	return err
+}
+
+func (l *literalClub) currentlyReading(ctx context.Context, count int) ([]literal.Book, error) {
+	books, err := l.client.CurrentlyReading(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(books) > count {
+		return books[:count], nil
+	}
+	return books, nil
+}
@@ -0,0 +1,197 @@
+package scribe
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Timing sums up the data calls made with a template function.
+type Timing struct {
+	Func  string
+	Calls int
+	// Failed is the number of calls that returned an error.
+	Failed int
+	// Items is the number of items the calls returned, e.g. repositories.
+	Items int
+	// Cached is the number of calls answered from a cache entirely.
+	Cached int
+	// Duration is the time spent on the calls, which may add up to more
+	// than the render took when they ran concurrently.
+	Duration time.Duration
+}
+
+// timings collects the Timing of every template function.
+type timings struct {
+	mu    sync.Mutex
+	funcs map[string]*Timing
+}
+
+// add counts a call of fn.
+func (t *timings) add(fn string, items int, failed, cached bool, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.funcs == nil {
+		t.funcs = map[string]*Timing{}
+	}
+	ft, ok := t.funcs[fn]
+	if !ok {
+		ft = &Timing{Func: fn}
+		t.funcs[fn] = ft
+	}
+
+	ft.Calls++
+	ft.Items += items
+	ft.Duration += d
+	if failed {
+		ft.Failed++
+	}
+	if cached {
+		ft.Cached++
+	}
+}
+
+// Timings returns how long the data calls of all renders took, by template
+// function, slowest first.
+func (s *Scribe) Timings() []Timing {
+	t := &s.state.timings
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	res := make([]Timing, 0, len(t.funcs))
+	for _, ft := range t.funcs {
+		res = append(res, *ft)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Duration == res[j].Duration {
+			return res[i].Func < res[j].Func
+		}
+		return res[i].Duration > res[j].Duration
+	})
+	return res
+}
+
+// callStats counts the HTTP requests made for a data call.
+type callStats struct {
+	requests atomic.Int32
+	cached   atomic.Int32
+}
+
+type callStatsKey struct{}
+
+// RecordRequest notes that the data call ctx belongs to made an HTTP request,
+// which was answered from a cache if cached is true. Caching transports call
+// it, so data calls get logged with their cache status, and cached rate
+// limits don't get counted again.
+func RecordRequest(ctx context.Context, cached bool) {
+	if q, ok := ctx.Value(queryCacheKey{}).(*atomic.Bool); ok && cached {
+		q.Store(true)
+	}
+
+	stats, ok := ctx.Value(callStatsKey{}).(*callStats)
+	if !ok {
+		return
+	}
+	stats.requests.Add(1)
+	if cached {
+		stats.cached.Add(1)
+	}
+}
+
+// cacheStatus describes how many of the recorded requests were answered from
+// a cache, or returns "" if there were none.
+func (c *callStats) cacheStatus() string {
+	requests, cached := c.requests.Load(), c.cached.Load()
+	switch {
+	case requests == 0:
+		return ""
+	case cached == requests:
+		return "hit"
+	case cached == 0:
+		return "miss"
+	}
+	return "partial"
+}
+
+// logger returns the logger of s, which discards everything by default.
+func (s *Scribe) logger() *slog.Logger {
+	if s.opts.Logger == nil {
+		return slog.New(discardHandler{})
+	}
+	return s.opts.Logger
+}
+
+// traceCall logs a call of the data function name of source, which took d
+// and returned res, and adds it to the timings.
+func (s *Scribe) traceCall(ctx context.Context, source, name string, args, res []reflect.Value, stats *callStats, d time.Duration) {
+	err, _ := res[len(res)-1].Interface().(error)
+	items := 0
+	if err == nil {
+		items = countItems(res[0])
+	}
+	cache := stats.cacheStatus()
+	s.state.timings.add(name, items, err != nil, cache == "hit", d)
+
+	attrs := []slog.Attr{
+		slog.String("source", source),
+		slog.String("call", formatCall(name, args)),
+		slog.Int("items", items),
+		slog.Duration("duration", d),
+	}
+	if len(cache) > 0 {
+		attrs = append(attrs, slog.String("cache", cache))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	s.logger().LogAttrs(ctx, slog.LevelInfo, "data call", attrs...)
+}
+
+// traceBatch logs a batched lookup of n calls of the data function name,
+// which took d and returned found items, and adds it to the timings. Errors
+// of single calls surface when they get made.
+func (s *Scribe) traceBatch(ctx context.Context, name string, n, found int, err error, stats *callStats, d time.Duration) {
+	cache := stats.cacheStatus()
+	s.state.timings.add(name+" (batched)", found, false, cache == "hit", d)
+
+	attrs := []slog.Attr{
+		slog.String("source", "github"),
+		slog.String("func", name),
+		slog.Int("calls", n),
+		slog.Int("items", found),
+		slog.Duration("duration", d),
+	}
+	if len(cache) > 0 {
+		attrs = append(attrs, slog.String("cache", cache))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("error", err))
+	}
+	s.logger().LogAttrs(ctx, slog.LevelInfo, "batched data calls", attrs...)
+}
+
+// countItems returns the number of items in the result v of a data call.
+func countItems(v reflect.Value) int {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return v.Len()
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+	}
+	return 1
+}
+
+// discardHandler is a slog.Handler dropping all records.
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
@@ -0,0 +1,98 @@
+package scribe
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// memoEntry is the result of a data call. It's ready once done is closed.
+type memoEntry struct {
+	done chan struct{}
+	res  []reflect.Value
+}
+
+// formatCall formats a call of the template function name with args.
+func formatCall(name string, args []reflect.Value) string {
+	s := make([]string, 0, len(args))
+	for _, a := range args {
+		s = append(s, fmt.Sprintf("%#v", a.Interface()))
+	}
+	return fmt.Sprintf("%s(%s)", name, strings.Join(s, ", "))
+}
+
+// callKey identifies a call of the template function name with args on
+// behalf of the user s describes.
+func (s *Scribe) callKey(name string, args []reflect.Value) string {
+	return s.opts.Username + ":" + formatCall(name, args)
+}
+
+// memoize wraps the template function fn, so that its results get stored in
+// the memo and are reused for identical calls. Identical calls made while the
+// first one is still in flight wait for its result instead of fetching the
+// same data again.
+func (s *Scribe) memoize(name string, fn interface{}) interface{} {
+	v := reflect.ValueOf(fn)
+	return reflect.MakeFunc(v.Type(), func(args []reflect.Value) []reflect.Value {
+		key := s.callKey(name, args)
+
+		s.state.memoMu.Lock()
+		e, ok := s.state.memo[key]
+		if !ok {
+			e = &memoEntry{done: make(chan struct{})}
+			s.state.memo[key] = e
+		}
+		s.state.memoMu.Unlock()
+
+		if !ok {
+			e.res = callSafely(name, v, args)
+			close(e.done)
+		}
+		<-e.done
+		return e.res
+	}).Interface()
+}
+
+// memoLookup returns the memoized result for key, waiting for it if the call
+// is still in flight.
+func (s *Scribe) memoLookup(key string) ([]reflect.Value, bool) {
+	s.state.memoMu.Lock()
+	e, ok := s.state.memo[key]
+	s.state.memoMu.Unlock()
+	if !ok {
+		return nil, false
+	}
+
+	<-e.done
+	return e.res, true
+}
+
+// memoStore stores res as the result for key, unless there already is one.
+func (s *Scribe) memoStore(key string, res []reflect.Value) {
+	s.state.memoMu.Lock()
+	defer s.state.memoMu.Unlock()
+	if _, ok := s.state.memo[key]; ok {
+		return
+	}
+
+	e := &memoEntry{done: make(chan struct{}), res: res}
+	close(e.done)
+	s.state.memo[key] = e
+}
+
+// callSafely calls fn with args. If fn panics, the panic is turned into the
+// error result of the call, so callers waiting for the result get one.
+func callSafely(name string, fn reflect.Value, args []reflect.Value) (res []reflect.Value) {
+	t := fn.Type()
+	if t.NumOut() != 2 || t.Out(1) != errorType {
+		return fn.Call(args)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err := fmt.Errorf("%s panicked: %v", name, r)
+			res = []reflect.Value{reflect.Zero(t.Out(0)), reflect.ValueOf(&err).Elem()}
+		}
+	}()
+	return fn.Call(args)
+}
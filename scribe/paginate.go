@@ -0,0 +1,45 @@
+package scribe
+
+import (
+	"github.com/shurcooL/githubv4"
+)
+
+// maxPageSize is the most nodes GitHub returns per page of a connection.
+const maxPageSize = 100
+
+// qlPageInfo tells where a page of a connection ends.
+type qlPageInfo struct {
+	EndCursor   githubv4.String
+	HasNextPage githubv4.Boolean
+}
+
+// paginate collects up to count items from a connection, page by page.
+// fetch gets called with the number of nodes to fetch and the cursor to
+// fetch them after, and returns the items made of the nodes that passed its
+// filters, along with the page info.
+func paginate[T any](count int, fetch func(first int, after *githubv4.String) ([]T, qlPageInfo, error)) ([]T, error) {
+	var items []T
+	var after *githubv4.String
+
+	for len(items) < count {
+		// one more than needed, as filters tend to drop an item, like the
+		// meta-repo
+		first := min(count-len(items), maxPageSize-1) + 1
+
+		page, info, err := fetch(first, after)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, page...)
+
+		if !info.HasNextPage {
+			break
+		}
+		after = githubv4.NewString(info.EndCursor)
+	}
+
+	if len(items) > count {
+		items = items[:count]
+	}
+	return items, nil
+}
@@ -0,0 +1,93 @@
+package scribe
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/shurcooL/githubv4"
+)
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name  string
+		nodes int
+		count int
+		// drop filters out every node divisible by it
+		drop int
+		// firsts are the page sizes requested
+		firsts []int
+		want   int
+	}{
+		{name: "single page", nodes: 250, count: 10, firsts: []int{11}, want: 10},
+		{name: "full page", nodes: 250, count: 99, firsts: []int{100}, want: 99},
+		{name: "page boundary", nodes: 250, count: 100, firsts: []int{100}, want: 100},
+		{name: "past page boundary", nodes: 250, count: 101, firsts: []int{100, 2}, want: 101},
+		{name: "several pages", nodes: 250, count: 210, firsts: []int{100, 100, 11}, want: 210},
+		{name: "filtered", nodes: 250, count: 100, drop: 10, firsts: []int{100, 11, 2}, want: 100},
+		{name: "runs out", nodes: 150, count: 200, firsts: []int{100, 100}, want: 150},
+		{name: "empty", nodes: 0, count: 10, firsts: []int{11}, want: 0},
+		{name: "nothing wanted", nodes: 250, count: 0, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var firsts []int
+			pos := 0
+			items, err := paginate(tt.count, func(first int, after *githubv4.String) ([]int, qlPageInfo, error) {
+				firsts = append(firsts, first)
+				if first > maxPageSize {
+					t.Fatalf("requested %d nodes, more than a page", first)
+				}
+				// every page continues where the last one ended
+				if (after == nil) != (pos == 0) || (after != nil && string(*after) != strconv.Itoa(pos)) {
+					t.Fatalf("page after %v, want after %d", after, pos)
+				}
+
+				var page []int
+				end := min(pos+first, tt.nodes)
+				for ; pos < end; pos++ {
+					if tt.drop > 0 && pos%tt.drop == 0 {
+						continue
+					}
+					page = append(page, pos)
+				}
+				return page, qlPageInfo{
+					EndCursor:   githubv4.String(strconv.Itoa(pos)),
+					HasNextPage: githubv4.Boolean(pos < tt.nodes),
+				}, nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(firsts, tt.firsts) {
+				t.Errorf("requested pages of %v, want %v", firsts, tt.firsts)
+			}
+			if len(items) != tt.want {
+				t.Fatalf("got %d items, want %d", len(items), tt.want)
+			}
+			// no node gets skipped or repeated across pages
+			for i := 1; i < len(items); i++ {
+				if d := items[i] - items[i-1]; d != 1 && (tt.drop == 0 || d != 2) {
+					t.Fatalf("item %d follows %d", items[i], items[i-1])
+				}
+			}
+		})
+	}
+}
+
+func TestPaginateError(t *testing.T) {
+	boom := errors.New("boom")
+	calls := 0
+	_, err := paginate(150, func(int, *githubv4.String) ([]int, qlPageInfo, error) {
+		calls++
+		if calls == 2 {
+			return nil, qlPageInfo{}, boom
+		}
+		return make([]int, 100), qlPageInfo{EndCursor: "100", HasNextPage: true}, nil
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("err = %v, want %v", err, boom)
+	}
+}
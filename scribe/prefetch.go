@@ -0,0 +1,227 @@
+package scribe
+
+import (
+	"reflect"
+	"sync"
+	"text/template"
+	"text/template/parse"
+)
+
+// dataCall is a call of a data function found in a template.
+type dataCall struct {
+	name string
+	args []reflect.Value
+}
+
+// findDataCalls returns the calls of the data functions in funcs that only
+// take constant arguments and that executing the templates called names in
+// tpl is sure to make, so they can be made before tpl gets executed. Calls in
+// the bodies of if, range and with are left out, as are templates that don't
+// get executed.
+func findDataCalls(tpl *template.Template, funcs template.FuncMap, names ...string) []dataCall {
+	var calls []dataCall
+	seen := map[string]bool{}
+
+	visit := func(cmd *parse.CommandNode) {
+		ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+		if !ok {
+			return
+		}
+		name, args := ident.Ident, cmd.Args[1:]
+
+		// try "name" args... calls name
+		if name == "try" && len(args) > 0 {
+			s, ok := args[0].(*parse.StringNode)
+			if !ok {
+				return
+			}
+			name, args = s.Text, args[1:]
+		}
+
+		fn, ok := funcs[name]
+		if !ok {
+			return
+		}
+		in, ok := constantArgs(reflect.TypeOf(fn), args)
+		if !ok {
+			return
+		}
+
+		key := formatCall(name, in)
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		calls = append(calls, dataCall{name: name, args: in})
+	}
+
+	executed := map[string]bool{}
+	var execute func(name string)
+	execute = func(name string) {
+		if executed[name] {
+			return
+		}
+		executed[name] = true
+
+		t := tpl.Lookup(name)
+		if t == nil || t.Tree == nil {
+			return
+		}
+		walkUnconditional(t.Tree.Root, visit, execute)
+	}
+	for _, name := range names {
+		execute(name)
+	}
+
+	return calls
+}
+
+// constantArgs converts the argument nodes of a call of a function of type t
+// to values. It reports false if any of them isn't a constant of the right
+// type.
+func constantArgs(t reflect.Type, args []parse.Node) ([]reflect.Value, bool) {
+	if t.NumIn() != len(args) || t.IsVariadic() {
+		return nil, false
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		p := t.In(i)
+		switch n := arg.(type) {
+		case *parse.StringNode:
+			if p.Kind() != reflect.String {
+				return nil, false
+			}
+			in[i] = reflect.ValueOf(n.Text).Convert(p)
+		case *parse.NumberNode:
+			if !n.IsInt || p.Kind() != reflect.Int {
+				return nil, false
+			}
+			in[i] = reflect.ValueOf(int(n.Int64)).Convert(p)
+		case *parse.BoolNode:
+			if p.Kind() != reflect.Bool {
+				return nil, false
+			}
+			in[i] = reflect.ValueOf(n.True).Convert(p)
+		default:
+			return nil, false
+		}
+	}
+	return in, true
+}
+
+// walkCommands calls visit for every command in the tree below node.
+func walkCommands(node parse.Node, visit func(*parse.CommandNode)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkCommands(c, visit)
+		}
+	case *parse.ActionNode:
+		walkCommands(n.Pipe, visit)
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, visit)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, visit)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, visit)
+	case *parse.TemplateNode:
+		walkCommands(n.Pipe, visit)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkCommands(c, visit)
+		}
+	case *parse.ChainNode:
+		walkCommands(n.Node, visit)
+	case *parse.CommandNode:
+		visit(n)
+		for _, arg := range n.Args {
+			walkCommands(arg, visit)
+		}
+	}
+}
+
+func walkBranch(n *parse.BranchNode, visit func(*parse.CommandNode)) {
+	walkCommands(n.Pipe, visit)
+	walkCommands(n.List, visit)
+	walkCommands(n.ElseList, visit)
+}
+
+// walkUnconditional calls visit for every command in the tree below node
+// that gets executed whenever node does, and execute for every template
+// those execute. It skips the bodies of if, range and with, and the operands
+// of and and or that might not get evaluated.
+func walkUnconditional(node parse.Node, visit func(*parse.CommandNode), execute func(string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Nodes {
+			walkUnconditional(c, visit, execute)
+		}
+	case *parse.ActionNode:
+		walkUnconditional(n.Pipe, visit, execute)
+	case *parse.IfNode:
+		walkUnconditional(n.Pipe, visit, execute)
+	case *parse.RangeNode:
+		walkUnconditional(n.Pipe, visit, execute)
+	case *parse.WithNode:
+		walkUnconditional(n.Pipe, visit, execute)
+	case *parse.TemplateNode:
+		walkUnconditional(n.Pipe, visit, execute)
+		execute(n.Name)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, c := range n.Cmds {
+			walkUnconditional(c, visit, execute)
+		}
+	case *parse.ChainNode:
+		walkUnconditional(n.Node, visit, execute)
+	case *parse.CommandNode:
+		visit(n)
+		args := n.Args
+		if ident, ok := args[0].(*parse.IdentifierNode); ok && (ident.Ident == "and" || ident.Ident == "or") {
+			// only the first operand is sure to be evaluated
+			args = args[:min(len(args), 2)]
+		}
+		for _, arg := range args {
+			walkUnconditional(arg, visit, execute)
+		}
+	}
+}
+
+// prefetch concurrently makes the constant data calls the templates called
+// names in tpl are sure to make, with up to limit calls at once. Their
+// results end up in memo, from where executing the template picks them up,
+// so the output is the same as if it had made all calls in order.
+func prefetch(tpl *template.Template, funcs template.FuncMap, limit int, names ...string) {
+	calls := findDataCalls(tpl, funcs, names...)
+	if limit <= 1 || len(calls) <= 1 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, limit)
+	for _, c := range calls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(c dataCall) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			// failures surface when the template makes the call itself
+			defer func() { _ = recover() }()
+
+			reflect.ValueOf(funcs[c.name]).Call(c.args)
+		}(c)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,136 @@
+package scribe
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestFindDataCalls(t *testing.T) {
+	funcs := template.FuncMap{
+		"rss": func(string, int) ([]string, error) { return nil, nil },
+		"try": func(string, ...interface{}) (interface{}, error) { return nil, nil },
+	}
+
+	tests := []struct {
+		name  string
+		tpl   string
+		names []string
+		want  []string
+	}{
+		{
+			name: "constant arguments",
+			tpl:  `{{range rss "a" 5}}{{.}}{{end}}`,
+			want: []string{`rss("a", 5)`},
+		},
+		{
+			name: "variable arguments",
+			tpl:  `{{range rss .url 5}}{{.}}{{end}}`,
+		},
+		{
+			name: "duplicates",
+			tpl:  `{{rss "a" 5}}{{rss "a" 5}}{{rss "b" 5}}`,
+			want: []string{`rss("a", 5)`, `rss("b", 5)`},
+		},
+		{
+			name: "try",
+			tpl:  `{{try "rss" "a" 5}}`,
+			want: []string{`rss("a", 5)`},
+		},
+		{
+			name: "if condition",
+			tpl:  `{{if rss "a" 5}}yes{{end}}`,
+			want: []string{`rss("a", 5)`},
+		},
+		{
+			name: "if body",
+			tpl:  `{{if .show}}{{range rss "a" 5}}{{.}}{{end}}{{else}}{{rss "b" 5}}{{end}}`,
+		},
+		{
+			name: "range and with bodies",
+			tpl:  `{{range .items}}{{rss "a" 5}}{{end}}{{with .feed}}{{rss "b" 5}}{{end}}`,
+		},
+		{
+			name: "and",
+			tpl:  `{{if and .show (rss "a" 5)}}yes{{end}}{{if and (rss "b" 5) .show}}yes{{end}}`,
+			want: []string{`rss("b", 5)`},
+		},
+		{
+			name: "or",
+			tpl:  `{{or .feed (rss "a" 5)}}`,
+		},
+		{
+			name: "unused define",
+			tpl:  `{{define "unused"}}{{rss "a" 5}}{{end}}main`,
+		},
+		{
+			name: "template call",
+			tpl:  `{{define "feed"}}{{rss "a" 5}}{{end}}{{template "feed"}}{{template "feed"}}`,
+			want: []string{`rss("a", 5)`},
+		},
+		{
+			name: "template call in a body",
+			tpl:  `{{define "feed"}}{{rss "a" 5}}{{end}}{{if .show}}{{template "feed"}}{{end}}`,
+		},
+		{
+			name:  "named templates",
+			tpl:   `{{define "a"}}{{rss "a" 5}}{{end}}{{define "b"}}{{rss "b" 5}}{{end}}{{define "c"}}{{rss "c" 5}}{{end}}{{rss "main" 5}}`,
+			names: []string{"a", "c", "missing"},
+			want:  []string{`rss("a", 5)`, `rss("c", 5)`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tpl, err := template.New("main").Funcs(funcs).Parse(tt.tpl)
+			if err != nil {
+				t.Fatal(err)
+			}
+			names := tt.names
+			if names == nil {
+				names = []string{"main"}
+			}
+
+			var got []string
+			for _, c := range findDataCalls(tpl, funcs, names...) {
+				got = append(got, formatCall(c.name, c.args))
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("findDataCalls() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrefetchKeepsOutput(t *testing.T) {
+	const text = `{{lookup "a"}} {{range .items}}{{lookup .}}{{end}} ` +
+		`{{if lookup "b"}}{{lookup "c"}}{{end}}{{if .hide}}{{lookup "hidden"}}{{end}} ` +
+		`{{template "t" .}}{{define "t"}}{{lookup "d"}}{{end}}{{define "unused"}}{{lookup "unused"}}{{end}}`
+	data := map[string]interface{}{"items": []string{"x", "y", "a"}, "hide": false}
+
+	render := func(parallel int) (string, []string) {
+		fake := &fakeSource{}
+		s := New(Options{Sources: []Source{fake}, Username: "muesli", Parallel: parallel})
+		tpl, err := s.Parse("test", text)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var b strings.Builder
+		if err := s.Render(context.Background(), tpl, &b, data); err != nil {
+			t.Fatal(err)
+		}
+		slices.Sort(fake.calls)
+		return b.String(), fake.calls
+	}
+
+	want, wantCalls := render(1)
+	got, calls := render(4)
+	if got != want {
+		t.Errorf("prefetched render = %q, want %q", got, want)
+	}
+	// prefetching doesn't make calls the render wouldn't
+	if !slices.Equal(calls, wantCalls) {
+		t.Errorf("prefetched render made calls %q, want %q", calls, wantCalls)
+	}
+}
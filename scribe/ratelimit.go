@@ -0,0 +1,311 @@
+package scribe
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+const (
+	// maxRetries is how often a failed GitHub request gets retried.
+	maxRetries = 4
+	// retryBaseDelay is the delay before the first retry, which doubles with
+	// every further one.
+	retryBaseDelay = time.Second
+	// maxRetryWait is the longest markscribe waits for a rate limit to
+	// reset. Requests hitting a limit that resets later fail right away.
+	maxRetryWait = time.Minute
+	// secondaryLimitDelay is how long to wait after hitting a secondary rate
+	// limit that doesn't say for how long it applies.
+	secondaryLimitDelay = time.Minute
+)
+
+// RateLimit describes the GitHub API rate limit, as of the last query.
+type RateLimit struct {
+	// Cost is the total cost of all queries made so far, in points.
+	Cost int
+	// Queries is the number of queries made so far.
+	Queries int
+	// Remaining is the number of points left until ResetAt.
+	Remaining int
+	ResetAt   time.Time
+}
+
+type qlRateLimit struct {
+	Cost      githubv4.Int
+	Remaining githubv4.Int
+	ResetAt   githubv4.DateTime
+}
+
+// queryCacheKey is the context key of the flag RecordRequest sets when the
+// response to a query came from a cache.
+type queryCacheKey struct{}
+
+// rateLimiter keeps track of the GitHub API rate limit.
+type rateLimiter struct {
+	mu    sync.Mutex
+	limit RateLimit
+}
+
+// update adds a query to the rate limit.
+func (l *rateLimiter) update(rl qlRateLimit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limit.Queries++
+	l.limit.Cost += int(rl.Cost)
+	switch {
+	case rl.ResetAt.After(l.limit.ResetAt):
+		// a new rate limit window
+		l.limit.Remaining = int(rl.Remaining)
+		l.limit.ResetAt = rl.ResetAt.Time
+	case rl.ResetAt.Equal(l.limit.ResetAt):
+		// queries made concurrently can finish in any order
+		l.limit.Remaining = min(l.limit.Remaining, int(rl.Remaining))
+	}
+}
+
+// get returns the rate limit, and whether any query was made yet.
+func (l *rateLimiter) get() (RateLimit, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit, l.limit.Queries > 0
+}
+
+// wait waits for the rate limit to reset if it's exhausted, unless that
+// takes longer than maxRetryWait.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	limit, ok := l.get()
+	if !ok || limit.Remaining > 0 || time.Now().After(limit.ResetAt) {
+		return nil
+	}
+
+	d := time.Until(limit.ResetAt)
+	if d > maxRetryWait {
+		return fmt.Errorf("GitHub API rate limit exhausted until %s", limit.ResetAt.Local().Format(time.Kitchen))
+	}
+	return sleep(ctx, d)
+}
+
+// query runs the GraphQL query q like githubv4.Client.Query does, but also
+// asks for the rate limit and keeps track of it:
+//
+//	query {
+//	  ... on Query { <q> }
+//	  rateLimit { cost remaining resetAt }
+//	}
+func (g *gitHub) query(ctx context.Context, q interface{}, variables map[string]interface{}) error {
+	if err := g.limiter.wait(ctx); err != nil {
+		return err
+	}
+
+	v := reflect.ValueOf(q).Elem()
+	wrapper := reflect.New(reflect.StructOf([]reflect.StructField{
+		{Name: "Query", Type: v.Type(), Tag: `graphql:"... on Query"`},
+		{Name: "RateLimit", Type: reflect.TypeOf(qlRateLimit{}), Tag: `graphql:"rateLimit"`},
+	})).Elem()
+
+	cached := &atomic.Bool{}
+	err := g.client.Query(context.WithValue(ctx, queryCacheKey{}, cached), wrapper.Addr().Interface(), variables)
+	// the data of a query that failed partially still gets decoded
+	v.Set(wrapper.Field(0))
+	// cached responses were counted when they were fetched
+	if rl := wrapper.Field(1).Interface().(qlRateLimit); !rl.ResetAt.IsZero() && !cached.Load() {
+		g.limiter.update(rl)
+	}
+	return err
+}
+
+// GitHubRateLimit returns the state of the GitHub API rate limit, and
+// whether s made any queries yet.
+func (s *Scribe) GitHubRateLimit() (RateLimit, bool) {
+	g, ok := s.sources["github"].(*gitHub)
+	if !ok {
+		return RateLimit{}, false
+	}
+	return g.limiter.get()
+}
+
+// retryTransport retries requests that failed with a transient error, like a
+// network error, or ran into a rate limit, with exponential backoff or after
+// as long as the server asks for. That includes GraphQL queries failing with a RATE_LIMITED
+// error, which GitHub answers with a status of 200.
+type retryTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 {
+			r = req.Clone(req.Context())
+			if req.Body != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		resp, err := t.next.RoundTrip(r)
+		// requests whose body can't be sent again can't be retried
+		last := attempt == maxRetries || (req.Body != nil && req.GetBody == nil)
+		if err != nil {
+			// network errors are transient, but canceled requests stay canceled
+			if last || req.Context().Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return nil, err
+			}
+			if err := sleep(req.Context(), retryBackoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if last {
+			return resp, nil
+		}
+
+		d, retry := retryDelay(resp, attempt)
+		if !retry {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close() //nolint: errcheck
+
+		if err := sleep(req.Context(), d); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryDelay reports whether the request that got resp is worth retrying,
+// and how long to wait before the retry. It may peek into the body of resp.
+func retryDelay(resp *http.Response, attempt int) (time.Duration, bool) {
+	backoff := retryBackoff(attempt)
+
+	switch resp.StatusCode {
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		if d, ok := retryAfter(resp.Header); ok {
+			return d, d <= maxRetryWait
+		}
+		if resp.Header.Get("X-Ratelimit-Remaining") == "0" {
+			return rateLimitReset(resp.Header)
+		}
+		if resp.StatusCode == http.StatusTooManyRequests || isSecondaryLimit(resp) {
+			return max(backoff, secondaryLimitDelay), true
+		}
+		// missing permissions
+		return 0, false
+
+	case http.StatusOK:
+		if !isRateLimited(resp) {
+			return 0, false
+		}
+		if d, ok := rateLimitReset(resp.Header); ok {
+			return d, true
+		}
+		return 0, false
+
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if d, ok := retryAfter(resp.Header); ok {
+			return d, d <= maxRetryWait
+		}
+		return backoff, true
+	}
+	return 0, false
+}
+
+// retryBackoff returns the delay before retrying a request that failed with a
+// transient error for the attempt+1th time.
+func retryBackoff(attempt int) time.Duration {
+	d := retryBaseDelay << attempt
+	return d + time.Duration(rand.Int63n(int64(d/2))) //nolint: gosec
+}
+
+// retryAfter returns the delay asked for by the Retry-After header, which
+// holds either seconds or a date.
+func retryAfter(h http.Header) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if len(v) == 0 {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(time.Until(t), 0), true
+	}
+	return 0, false
+}
+
+// rateLimitReset returns how long until the rate limit resets, according to
+// the X-Ratelimit-Reset header, and whether that's soon enough to wait for.
+func rateLimitReset(h http.Header) (time.Duration, bool) {
+	reset, err := strconv.ParseInt(h.Get("X-Ratelimit-Reset"), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	d := time.Until(time.Unix(reset, 0))
+	return max(d, 0), d <= maxRetryWait
+}
+
+// isRateLimited reports whether resp holds a GraphQL RATE_LIMITED error. The
+// body of resp stays readable.
+func isRateLimited(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint: errcheck
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil || !bytes.Contains(body, []byte("RATE_LIMITED")) {
+		return false
+	}
+
+	var r struct {
+		Errors []struct {
+			Type string `json:"type"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &r); err != nil {
+		return false
+	}
+	for _, e := range r.Errors {
+		if e.Type == "RATE_LIMITED" {
+			return true
+		}
+	}
+	return false
+}
+
+// isSecondaryLimit reports whether resp says a secondary rate limit was
+// hit. The body of resp stays readable.
+func isSecondaryLimit(resp *http.Response) bool {
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close() //nolint: errcheck
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	return err == nil && bytes.Contains(bytes.ToLower(body), []byte("secondary rate limit"))
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,299 @@
+package scribe
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// within reports whether d is within a second of want, leaving room for the
+// time tests take.
+func within(d, want time.Duration) bool {
+	return d <= want && d > want-time.Second
+}
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+		ok     bool
+	}{
+		{"missing", "", 0, false},
+		{"seconds", "30", 30 * time.Second, true},
+		{"zero", "0", 0, true},
+		{"date", time.Now().Add(20 * time.Second).UTC().Format(http.TimeFormat), 20 * time.Second, true},
+		{"past date", time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat), 0, true},
+		{"garbage", "soon", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			if len(tt.header) > 0 {
+				h.Set("Retry-After", tt.header)
+			}
+			d, ok := retryAfter(h)
+			if ok != tt.ok || (tt.want == 0 && d != 0) || (tt.want > 0 && !within(d, tt.want)) {
+				t.Errorf("retryAfter(%q) = %s, %t, want %s, %t", tt.header, d, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	inSecs := func(d time.Duration) string {
+		return strconv.FormatInt(time.Now().Add(d).Unix(), 10)
+	}
+
+	tests := []struct {
+		name   string
+		status int
+		header map[string]string
+		body   string
+		// want is the delay, or for backoffs the least delay
+		want    time.Duration
+		backoff bool
+		retry   bool
+	}{
+		{
+			name:   "retry-after seconds",
+			status: http.StatusForbidden,
+			header: map[string]string{"Retry-After": "30"},
+			want:   30 * time.Second,
+			retry:  true,
+		},
+		{
+			name:   "retry-after date",
+			status: http.StatusTooManyRequests,
+			header: map[string]string{"Retry-After": time.Now().Add(20 * time.Second).UTC().Format(http.TimeFormat)},
+			want:   20 * time.Second,
+			retry:  true,
+		},
+		{
+			name:   "retry-after too long",
+			status: http.StatusForbidden,
+			header: map[string]string{"Retry-After": "3600"},
+			want:   time.Hour,
+			retry:  false,
+		},
+		{
+			name:   "rate limit reset",
+			status: http.StatusForbidden,
+			header: map[string]string{"X-Ratelimit-Remaining": "0", "X-Ratelimit-Reset": inSecs(10 * time.Second)},
+			want:   10 * time.Second,
+			retry:  true,
+		},
+		{
+			name:   "rate limit reset too late",
+			status: http.StatusForbidden,
+			header: map[string]string{"X-Ratelimit-Remaining": "0", "X-Ratelimit-Reset": inSecs(2 * time.Hour)},
+			want:   2 * time.Hour,
+			retry:  false,
+		},
+		{
+			name:   "secondary rate limit",
+			status: http.StatusForbidden,
+			body:   "You have exceeded a secondary rate limit.",
+			want:   secondaryLimitDelay,
+			retry:  true,
+		},
+		{
+			name:   "missing permissions",
+			status: http.StatusForbidden,
+			body:   "Resource not accessible by integration",
+			retry:  false,
+		},
+		{
+			name:    "bad gateway",
+			status:  http.StatusBadGateway,
+			want:    retryBaseDelay,
+			backoff: true,
+			retry:   true,
+		},
+		{
+			name:   "graphql rate limited",
+			status: http.StatusOK,
+			header: map[string]string{"X-Ratelimit-Remaining": "0", "X-Ratelimit-Reset": inSecs(10 * time.Second)},
+			body:   `{"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`,
+			want:   10 * time.Second,
+			retry:  true,
+		},
+		{
+			name:   "graphql other error",
+			status: http.StatusOK,
+			body:   `{"errors":[{"type":"NOT_FOUND","message":"Could not resolve to a User"}]}`,
+			retry:  false,
+		},
+		{
+			name:   "ok",
+			status: http.StatusOK,
+			body:   `{"data":{}}`,
+			retry:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				StatusCode: tt.status,
+				Header:     http.Header{},
+				Body:       io.NopCloser(strings.NewReader(tt.body)),
+			}
+			for k, v := range tt.header {
+				resp.Header.Set(k, v)
+			}
+
+			d, retry := retryDelay(resp, 0)
+			if retry != tt.retry {
+				t.Fatalf("retry = %t, want %t", retry, tt.retry)
+			}
+			switch {
+			case !tt.retry:
+			case tt.backoff && (d < tt.want || d >= tt.want*3/2):
+				t.Errorf("delay = %s, want a backoff from %s", d, tt.want)
+			case !tt.backoff && !within(d, tt.want):
+				t.Errorf("delay = %s, want %s", d, tt.want)
+			}
+
+			// the body stays readable for the client
+			if b, _ := io.ReadAll(resp.Body); len(tt.body) > 0 && string(b) != tt.body {
+				t.Errorf("body = %q, want %q", b, tt.body)
+			}
+		})
+	}
+}
+
+func TestRetryTransportMaxAttempts(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	c := &http.Client{Transport: &retryTransport{next: http.DefaultTransport}}
+	resp, err := c.Post(srv.URL, "application/json", strings.NewReader(`{"query":"{viewer{login}}"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close() //nolint: errcheck
+
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadGateway)
+	}
+	if n := requests.Load(); n != maxRetries+1 {
+		t.Errorf("made %d requests, want %d", n, maxRetries+1)
+	}
+}
+
+// flakyTransport fails the first fails requests with err, and sends the
+// rest on to http.DefaultTransport.
+type flakyTransport struct {
+	fails    int32
+	err      error
+	requests atomic.Int32
+}
+
+func (f *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if f.requests.Add(1) <= f.fails {
+		return nil, f.err
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestRetryTransportNetworkErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		io.WriteString(w, `{"data":{}}`) //nolint: errcheck
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		err  error
+		// want is the number of requests made
+		want    int32
+		wantErr bool
+	}{
+		{name: "connection reset", err: syscall.ECONNRESET, want: 2},
+		{name: "unexpected EOF", err: io.ErrUnexpectedEOF, want: 2},
+		{name: "canceled", err: context.Canceled, want: 1, wantErr: true},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, want: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flaky := &flakyTransport{fails: 1, err: tt.err}
+			c := &http.Client{Transport: &retryTransport{next: flaky}}
+			start := time.Now()
+			resp, err := c.Post(srv.URL, "application/json", strings.NewReader(`{"query":"{viewer{login}}"}`))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want one: %t", err, tt.wantErr)
+			}
+			if err == nil {
+				if b, _ := io.ReadAll(resp.Body); string(b) != `{"data":{}}` {
+					t.Errorf("body = %q", b)
+				}
+				resp.Body.Close() //nolint: errcheck
+			}
+
+			if n := flaky.requests.Load(); n != tt.want {
+				t.Errorf("made %d requests, want %d", n, tt.want)
+			}
+			// retries back off like for other transient errors
+			if d := time.Since(start); tt.want > 1 && d < retryBaseDelay {
+				t.Errorf("retried after %s, want at least %s", d, retryBaseDelay)
+			}
+		})
+	}
+}
+
+// cachingTransport marks every request as answered from a cache, like the
+// cache of the markscribe command does for hits.
+type cachingTransport struct{}
+
+func (cachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	RecordRequest(req.Context(), true)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestQuerySkipsCachedRateLimits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"viewer":{"login":"me"},"rateLimit":{"cost":1,"remaining":4999,"resetAt":"2030-01-01T00:00:00Z"}}}`) //nolint: errcheck
+	}))
+	defer srv.Close()
+
+	for _, tt := range []struct {
+		name      string
+		transport http.RoundTripper
+		queries   int
+	}{
+		{"fetched", http.DefaultTransport, 1},
+		{"cached", cachingTransport{}, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			src, err := NewSource("github", SourceConfig{
+				Client:   &http.Client{Transport: tt.transport},
+				Settings: map[string]string{"url": srv.URL + "/graphql"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			s := New(Options{Sources: []Source{src}})
+			if _, err := s.Username(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			limit, _ := s.GitHubRateLimit()
+			if limit.Queries != tt.queries {
+				t.Errorf("counted %d queries, want %d", limit.Queries, tt.queries)
+			}
+		})
+	}
+}
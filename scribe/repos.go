@@ -0,0 +1,660 @@
+package scribe
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+type recentContributionsQuery struct {
+	User struct {
+		Login                   githubv4.String
+		ContributionsCollection struct {
+			ContributionYears               []githubv4.Int
+			CommitContributionsByRepository []struct {
+				Contributions struct {
+					Edges []struct {
+						Cursor githubv4.String
+						Node   struct {
+							OccurredAt githubv4.DateTime
+						}
+					}
+				} `graphql:"contributions(first: 1)"`
+				Repository qlRepository
+			} `graphql:"commitContributionsByRepository(maxRepositories: 100)"`
+		} `graphql:"contributionsCollection(from: $from, to: $to)"`
+	} `graphql:"user(login:$username)"`
+}
+
+type recentPullRequestsQuery struct {
+	User struct {
+		Login        githubv4.String
+		PullRequests struct {
+			TotalCount githubv4.Int
+			Edges      []struct {
+				Cursor githubv4.String
+				Node   qlPullRequest
+			}
+			PageInfo qlPageInfo
+		} `graphql:"pullRequests(first: $count, after: $after, orderBy: {field: CREATED_AT, direction: DESC})"`
+	} `graphql:"user(login:$username)"`
+}
+
+type recentReposQuery struct {
+	User struct {
+		Login        githubv4.String
+		Repositories struct {
+			TotalCount githubv4.Int
+			Edges      []struct {
+				Cursor githubv4.String
+				Node   qlRepository
+			}
+			PageInfo qlPageInfo
+		} `graphql:"repositories(first: $count, after: $after, privacy: PUBLIC, isFork: $isFork, ownerAffiliations: OWNER, orderBy: {field: CREATED_AT, direction: DESC})"`
+	} `graphql:"repositoryOwner(login: $owner)"`
+}
+
+type recentReleasesQuery struct {
+	User struct {
+		Login                     githubv4.String
+		RepositoriesContributedTo struct {
+			TotalCount githubv4.Int
+			Edges      []struct {
+				Cursor githubv4.String
+				Node   struct {
+					qlRepository
+					Releases qlReleases `graphql:"releases(first: 10, orderBy: {field: CREATED_AT, direction: DESC})"`
+				}
+			}
+			PageInfo qlPageInfo
+		} `graphql:"repositoriesContributedTo(first: $count, after:$after includeUserRepositories: true, contributionTypes: COMMIT, privacy: PUBLIC)"`
+	} `graphql:"user(login:$username)"`
+}
+
+/*
+Order by stars
+
+	{
+	  repositoryOwner(login: "charmbracelet") {
+	    id
+	    login
+	    repositories(
+	      first: 5
+	      privacy: PUBLIC
+	      orderBy: {field: STARGAZERS, direction: DESC}
+	    ) {
+	      edges {
+	        node {
+	          name
+	          description
+	          url
+	        }
+	      }
+	    }
+	  }
+	}
+*/
+func (g *gitHub) popularRepos(ctx context.Context, owner string, count int) ([]Repo, error) {
+	login, err := g.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return paginate(count, func(first int, after *githubv4.String) ([]Repo, qlPageInfo, error) {
+		var query struct {
+			Owner struct {
+				Repositories struct {
+					Edges []struct {
+						Node qlRepository
+					}
+					PageInfo qlPageInfo
+				} `graphql:"repositories(first: $count, after: $after, privacy: PUBLIC, orderBy: {field: STARGAZERS, direction: DESC})"`
+			} `graphql:"repositoryOwner(login: $owner)"`
+		}
+		variables := map[string]interface{}{
+			"owner": githubv4.String(owner),
+			"count": githubv4.Int(first),
+			"after": after,
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, qlPageInfo{}, err
+		}
+
+		var repos []Repo
+		for _, v := range query.Owner.Repositories.Edges {
+			// ignore meta-repo
+			if string(v.Node.NameWithOwner) == fmt.Sprintf("%s/%s", owner, login) {
+				continue
+			}
+			repos = append(repos, repoFromQL(v.Node))
+		}
+		return repos, query.Owner.Repositories.PageInfo, nil
+	})
+}
+
+// qlRepositoryWithRelease is a repository along with its latest release.
+type qlRepositoryWithRelease struct {
+	Description githubv4.String
+	Owner       struct {
+		Login githubv4.String
+	}
+	Name          githubv4.String
+	NameWithOwner githubv4.String
+	IsPrivate     githubv4.Boolean
+	URL           githubv4.String
+	Stargazers    struct {
+		TotalCount githubv4.Int
+	}
+	Releases qlReleases `graphql:"releases(first: 1)"`
+}
+
+type repoQuery struct {
+	Repository qlRepositoryWithRelease `graphql:"repository(owner:$owner, name:$name)"`
+}
+
+type repoRecentReleasesQuery struct {
+	Repository struct {
+		Releases struct {
+			qlReleases
+			PageInfo qlPageInfo
+		} `graphql:"releases(first: $count, after: $after, orderBy: {field: CREATED_AT, direction: DESC})"`
+	} `graphql:"repository(name: $name, owner: $owner)"`
+}
+
+func (g *gitHub) recentContributions(ctx context.Context, count int) ([]Contribution, error) {
+	login, err := g.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.recentContributionsOf(ctx, login, count)
+}
+
+func (g *gitHub) recentContributionsOf(ctx context.Context, login string, count int) ([]Contribution, error) {
+	// a contributions collection spans a year at most, and doesn't page
+	// through the repositories, so go back in time a year at a time until
+	// there are enough
+	latest := map[string]Contribution{}
+	var from, to *githubv4.DateTime
+	var years []githubv4.Int
+
+	for first := true; ; first = false {
+		var query recentContributionsQuery
+		variables := map[string]interface{}{
+			"username": githubv4.String(login),
+			"from":     from,
+			"to":       to,
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range query.User.ContributionsCollection.CommitContributionsByRepository {
+			// ignore meta-repo
+			if string(v.Repository.NameWithOwner) == fmt.Sprintf("%s/%s", login, login) {
+				continue
+			}
+			if v.Repository.IsPrivate {
+				continue
+			}
+			if len(v.Contributions.Edges) == 0 {
+				continue
+			}
+
+			c := Contribution{
+				Repo:       repoFromQL(v.Repository),
+				OccurredAt: v.Contributions.Edges[0].Node.OccurredAt.Time,
+			}
+			if prev, ok := latest[c.Repo.NameWithOwner]; !ok || c.OccurredAt.After(prev.OccurredAt) {
+				latest[c.Repo.NameWithOwner] = c
+			}
+		}
+
+		if first {
+			// the default collection covers the past year, the current
+			// calendar year included
+			for _, y := range query.User.ContributionsCollection.ContributionYears {
+				if int(y) < time.Now().Year() {
+					years = append(years, y)
+				}
+			}
+		}
+		if len(latest) >= count || len(years) == 0 {
+			break
+		}
+
+		start := time.Date(int(years[0]), time.January, 1, 0, 0, 0, 0, time.UTC)
+		from = &githubv4.DateTime{Time: start}
+		to = &githubv4.DateTime{Time: start.AddDate(1, 0, 0).Add(-time.Second)}
+		years = years[1:]
+	}
+
+	contributions := make([]Contribution, 0, len(latest))
+	for _, c := range latest {
+		contributions = append(contributions, c)
+	}
+
+	sort.Slice(contributions, func(i, j int) bool {
+		if contributions[i].OccurredAt.Equal(contributions[j].OccurredAt) {
+			return contributions[i].Repo.NameWithOwner < contributions[j].Repo.NameWithOwner
+		}
+		return contributions[i].OccurredAt.After(contributions[j].OccurredAt)
+	})
+
+	if len(contributions) > count {
+		return contributions[:count], nil
+	}
+	return contributions, nil
+}
+
+func (g *gitHub) recentPullRequests(ctx context.Context, count int) ([]PullRequest, error) {
+	login, err := g.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.recentPullRequestsOf(ctx, login, count)
+}
+
+func (g *gitHub) recentPullRequestsOf(ctx context.Context, login string, count int) ([]PullRequest, error) {
+	return paginate(count, func(first int, after *githubv4.String) ([]PullRequest, qlPageInfo, error) {
+		var query recentPullRequestsQuery
+		variables := map[string]interface{}{
+			"username": githubv4.String(login),
+			"count":    githubv4.Int(first),
+			"after":    after,
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, qlPageInfo{}, err
+		}
+
+		var pullRequests []PullRequest
+		for _, v := range query.User.PullRequests.Edges {
+			// ignore meta-repo
+			if string(v.Node.Repository.NameWithOwner) == fmt.Sprintf("%s/%s", login, login) {
+				continue
+			}
+			if v.Node.Repository.IsPrivate {
+				continue
+			}
+
+			pullRequests = append(pullRequests, pullRequestFromQL(v.Node))
+		}
+		return pullRequests, query.User.PullRequests.PageInfo, nil
+	})
+}
+
+func (g *gitHub) recentCreatedRepos(ctx context.Context, owner string, count int) ([]Repo, error) {
+	return g.recentRepos(ctx, owner, count, false)
+}
+
+func (g *gitHub) recentForkedRepos(ctx context.Context, owner string, count int) ([]Repo, error) {
+	return g.recentRepos(ctx, owner, count, true)
+}
+
+// recentRepos returns the repositories owner created most recently, either
+// forks or not.
+func (g *gitHub) recentRepos(ctx context.Context, owner string, count int, isFork bool) ([]Repo, error) {
+	return paginate(count, func(first int, after *githubv4.String) ([]Repo, qlPageInfo, error) {
+		var query recentReposQuery
+		variables := map[string]interface{}{
+			"owner":  githubv4.String(owner),
+			"count":  githubv4.Int(first),
+			"after":  after,
+			"isFork": githubv4.Boolean(isFork),
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, qlPageInfo{}, err
+		}
+
+		var repos []Repo
+		for _, v := range query.User.Repositories.Edges {
+			// ignore meta-repo
+			if string(v.Node.NameWithOwner) == fmt.Sprintf("%s/%s", owner, owner) {
+				continue
+			}
+			repos = append(repos, repoFromQL(v.Node))
+		}
+		return repos, query.User.Repositories.PageInfo, nil
+	})
+}
+
+func (g *gitHub) latestReleasedRepos(ctx context.Context, owner string, count int) ([]Repo, error) {
+	// look for releases in at least the 100 most recently updated repos
+	updated, err := paginate(max(count, maxPageSize), func(first int, after *githubv4.String) ([]Repo, qlPageInfo, error) {
+		var query struct {
+			Owner struct {
+				Repositories struct {
+					Edges []struct {
+						Cursor githubv4.String
+						Node   struct {
+							qlRepository
+							Release qlRelease `graphql:"latestRelease"`
+						}
+					}
+					PageInfo qlPageInfo
+				} `graphql:"repositories(first: $count, after: $after, privacy: PUBLIC, orderBy: {field: UPDATED_AT, direction: DESC})"`
+			} `graphql:"repositoryOwner(login: $owner)"`
+		}
+		variables := map[string]interface{}{
+			"owner": githubv4.String(owner),
+			"count": githubv4.Int(first),
+			"after": after,
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, qlPageInfo{}, err
+		}
+
+		var repos []Repo
+		for _, v := range query.Owner.Repositories.Edges {
+			repo := repoFromQL(v.Node.qlRepository)
+			repo.LastRelease = releaseFromQL(v.Node.Release)
+			repos = append(repos, repo)
+		}
+		return repos, query.Owner.Repositories.PageInfo, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var repos []Repo
+	for _, repo := range updated {
+		if repo.LastRelease.Name != "" {
+			repos = append(repos, repo)
+		}
+	}
+
+	slices.SortFunc(repos, func(a, b Repo) int {
+		return a.LastRelease.PublishedAt.Compare(b.LastRelease.PublishedAt)
+	})
+	slices.Reverse(repos)
+	if len(repos) > count {
+		return repos[:count], nil
+	}
+	return repos, nil
+}
+
+func (g *gitHub) recentReleases(ctx context.Context, count int) ([]Repo, error) {
+	login, err := g.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.recentReleasesOf(ctx, login, count)
+}
+
+func (g *gitHub) recentReleasesOf(ctx context.Context, login string, count int) ([]Repo, error) {
+	// the latest releases can be in any of the repos, so look at all of them
+	repos, err := paginate(math.MaxInt, func(first int, after *githubv4.String) ([]Repo, qlPageInfo, error) {
+		var query recentReleasesQuery
+		variables := map[string]interface{}{
+			"username": githubv4.String(login),
+			"count":    githubv4.Int(first),
+			"after":    after,
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, qlPageInfo{}, err
+		}
+
+		var repos []Repo
+		for _, v := range query.User.RepositoriesContributedTo.Edges {
+			r := repoFromQL(v.Node.qlRepository)
+
+			for _, rel := range v.Node.Releases.Nodes {
+				if rel.IsPrerelease || rel.IsDraft {
+					continue
+				}
+				if v.Node.Releases.Nodes[0].TagName == "" ||
+					v.Node.Releases.Nodes[0].PublishedAt.Time.IsZero() {
+					continue
+				}
+				r.LastRelease = releasesFromQL(v.Node.Releases)
+				break
+			}
+
+			if !r.LastRelease.PublishedAt.IsZero() {
+				repos = append(repos, r)
+			}
+		}
+		return repos, query.User.RepositoriesContributedTo.PageInfo, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(repos, func(i, j int) bool {
+		if repos[i].LastRelease.PublishedAt.Equal(repos[j].LastRelease.PublishedAt) {
+			return repos[i].Stargazers > repos[j].Stargazers
+		}
+		return repos[i].LastRelease.PublishedAt.After(repos[j].LastRelease.PublishedAt)
+	})
+
+	if len(repos) > count {
+		return repos[:count], nil
+	}
+	return repos, nil
+}
+
+/*
+	{
+		repositoryOwner(login: "charmbracelet") {
+		id
+		login
+		repositories(
+			first: 5
+			privacy: PUBLIC
+			orderBy: {field: PUSHED_AT, direction: DESC}
+		) {
+			edges {
+			node {
+				name
+				description
+				url
+			}
+			}
+		}
+		}
+	}
+*/
+
+type RepoWithPushedAt struct {
+	Repo
+	PushedAt time.Time
+}
+
+func (g *gitHub) recentPushedRepos(ctx context.Context, owner string, count int) ([]RepoWithPushedAt, error) {
+	type qlRepoWithPushedAt struct {
+		qlRepository
+		PushedAt githubv4.DateTime
+	}
+
+	return paginate(count, func(first int, after *githubv4.String) ([]RepoWithPushedAt, qlPageInfo, error) {
+		var query struct {
+			Owner struct {
+				Repositories struct {
+					Edges []struct {
+						Node qlRepoWithPushedAt
+					}
+					PageInfo qlPageInfo
+				} `graphql:"repositories(first: $count, after: $after, privacy: PUBLIC, orderBy: {field: PUSHED_AT, direction: DESC})"`
+			} `graphql:"repositoryOwner(login: $owner)"`
+		}
+		variables := map[string]interface{}{
+			"count": githubv4.Int(first),
+			"after": after,
+			"owner": githubv4.String(owner),
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, qlPageInfo{}, err
+		}
+
+		var repos []RepoWithPushedAt
+		for _, v := range query.Owner.Repositories.Edges {
+			repo := RepoWithPushedAt{
+				PushedAt: v.Node.PushedAt.Time,
+			}
+			repo.Owner = string(v.Node.Owner.Login)
+			repo.Name = string(v.Node.Name)
+			repo.NameWithOwner = string(v.Node.NameWithOwner)
+			repo.URL = string(v.Node.URL)
+			repo.Description = string(v.Node.Description)
+			repo.Stargazers = int(v.Node.Stargazers.TotalCount)
+			repo.IsPrivate = bool(v.Node.IsPrivate)
+
+			repos = append(repos, repo)
+		}
+		return repos, query.Owner.Repositories.PageInfo, nil
+	})
+}
+
+func (g *gitHub) repo(ctx context.Context, owner, name string) (Repo, error) {
+	var query repoQuery
+	variables := map[string]interface{}{
+		"owner": githubv4.String(owner),
+		"name":  githubv4.String(name),
+	}
+	err := g.query(ctx, &query, variables)
+	if err != nil {
+		return Repo{}, err
+	}
+	return repoWithReleaseFromQL(query.Repository), nil
+}
+
+func repoWithReleaseFromQL(repo qlRepositoryWithRelease) Repo {
+	return Repo{
+		Owner:         string(repo.Owner.Login),
+		Name:          string(repo.Name),
+		NameWithOwner: string(repo.NameWithOwner),
+		URL:           string(repo.URL),
+		Description:   string(repo.Description),
+		Stargazers:    int(repo.Stargazers.TotalCount),
+		IsPrivate:     bool(repo.IsPrivate),
+		LastRelease:   releasesFromQL(repo.Releases),
+	}
+}
+
+func (g *gitHub) repoRecentReleases(ctx context.Context, owner, name string, count int) ([]Release, error) {
+	return paginate(count, func(first int, after *githubv4.String) ([]Release, qlPageInfo, error) {
+		var query repoRecentReleasesQuery
+		variables := map[string]interface{}{
+			"owner": githubv4.String(owner),
+			"name":  githubv4.String(name),
+			"count": githubv4.Int(first),
+			"after": after,
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, qlPageInfo{}, err
+		}
+
+		var releases []Release
+		for _, rel := range query.Repository.Releases.Nodes {
+			if bool(rel.IsPrerelease) {
+				continue
+			}
+			releases = append(releases, Release{
+				Name:         string(rel.Name),
+				TagName:      string(rel.TagName),
+				PublishedAt:  rel.PublishedAt.Time,
+				CreatedAt:    rel.CreatedAt.Time,
+				URL:          string(rel.URL),
+				IsLatest:     bool(rel.IsLatest),
+				IsPreRelease: bool(rel.IsPrerelease),
+				IsDraft:      bool(rel.IsDraft),
+			})
+		}
+		return releases, query.Repository.Releases.PageInfo, nil
+	})
+}
+
+/*
+{
+  user(login: "muesli") {
+    login
+    repositoriesContributedTo(first: 100, includeUserRepositories: true, contributionTypes: COMMIT) {
+      totalCount
+      edges {
+        cursor
+        node {
+          id
+          nameWithOwner
+        }
+      }
+    }
+  }
+}
+
+{
+  user(login: "muesli") {
+    login
+    repositoriesContributedTo(first: 100, includeUserRepositories: true, contributionTypes: COMMIT) {
+      totalCount
+      edges {
+        cursor
+        node {
+          id
+          nameWithOwner
+		  releases(first: 3, orderBy: {field: CREATED_AT, direction: DESC}) {
+          	nodes {
+          	  name
+              PublishedAt
+			  url
+			  isPrerelease
+			  isDraft
+            }
+          }
+        }
+      }
+    }
+  }
+}
+
+{
+  user(login: "muesli") {
+    login
+    repositories(first: 10, privacy: PUBLIC, isFork: false, ownerAffiliations: OWNER, orderBy: {field: CREATED_AT, direction: DESC}) {
+      totalCount
+      edges {
+        cursor
+        node {
+          id
+          nameWithOwner
+        }
+      }
+    }
+  }
+}
+
+{
+  user(login: "muesli") {
+    login
+    contributionsCollection {
+      commitContributionsByRepository {
+        contributions(first: 1) {
+          edges {
+            cursor
+            node {
+              occurredAt
+            }
+          }
+        }
+        repository {
+          id
+		  nameWithOwner
+		  url
+		  description
+        }
+      }
+    }
+  }
+}
+*/
@@ -0,0 +1,97 @@
+package scribe
+
+import (
+	"context"
+	"net/http"
+	"text/template"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func init() {
+	RegisterSource(func() Source { return &rss{} })
+}
+
+// rss is the source for RSS and Atom feeds.
+type rss struct {
+	client *http.Client
+}
+
+func (r *rss) Name() string {
+	return "rss"
+}
+
+func (r *rss) Settings() []Setting {
+	return nil
+}
+
+func (r *rss) Configure(cfg SourceConfig) error {
+	r.client = cfg.client()
+	return nil
+}
+
+func (r *rss) Funcs(*Scribe) template.FuncMap {
+	return template.FuncMap{
+		"rss": r.feed,
+	}
+}
+
+func (r *rss) FuncDocs() map[string]FuncDoc {
+	return map[string]FuncDoc{
+		"rss": {
+			Params:  []string{"url", "count"},
+			Summary: "The latest entries of the RSS or Atom feed at url.",
+			Example: `{{range rss "https://domain.tld/feed.xml" 5}}[{{.Title}}]({{.URL}}) {{humanize .PublishedAt}}{{end}}`,
+		},
+	}
+}
+
+// Check does nothing, as feeds are only known once templates ask for them.
+func (r *rss) Check(context.Context) error {
+	return nil
+}
+
+// RSSEntry represents a single RSS entry.
+type RSSEntry struct {
+	Title       string
+	Author      string
+	Description string
+	URL         string
+	PublishedAt time.Time
+}
+
+func (r *rss) feed(ctx context.Context, url string, count int) ([]RSSEntry, error) {
+	var entries []RSSEntry
+
+	fp := gofeed.NewParser()
+	fp.Client = r.client
+	feed, err := fp.ParseURLWithContext(url, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range feed.Items {
+		entry := RSSEntry{
+			Title:       v.Title,
+			Description: v.Description,
+			URL:         v.Link,
+		}
+		if v.Author != nil {
+			entry.Author = v.Author.Name
+		}
+		switch {
+		case v.PublishedParsed != nil:
+			entry.PublishedAt = *v.PublishedParsed
+		case v.UpdatedParsed != nil:
+			entry.PublishedAt = *v.UpdatedParsed
+		}
+
+		entries = append(entries, entry)
+		if len(entries) == count {
+			break
+		}
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,298 @@
+// Package scribe renders markscribe templates: Go text templates with
+// functions fetching data from GitHub, RSS feeds, GoodReads and literal.club.
+//
+//	gh, err := scribe.NewSource("github", scribe.SourceConfig{
+//		Settings: map[string]string{"token": token},
+//	})
+//	...
+//	s := scribe.New(scribe.Options{Sources: []scribe.Source{gh}})
+//	tpl, err := s.Parse("README.md.tpl", text)
+//	...
+//	err = s.Render(ctx, tpl, os.Stdout, nil)
+package scribe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"reflect"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/go-sprout/sprout"
+)
+
+// defaultParallel is how many data calls Render makes at once, unless
+// configured otherwise.
+const defaultParallel = 4
+
+// Options configures a Scribe.
+type Options struct {
+	// Sources are the sources templates can fetch data from, see NewSource.
+	// Defaults to all registered sources, without any settings. The
+	// functions of registered sources left out fail when called.
+	Sources []Source
+
+	// Username is the GitHub user whose data functions like recentStars
+	// describe. Defaults to the user the github source is authenticated as.
+	// Functions like recentStarsOf describe any user.
+	Username string
+
+	// Timeouts limits how long a single data call may take, by source name.
+	// The empty name holds the limit for all other sources. Data calls
+	// aren't limited by default, other than by the context of the render.
+	Timeouts map[string]time.Duration
+
+	// KeepGoing makes failing data calls return their zero value instead of
+	// stopping the render. The failures are reported by Failures.
+	KeepGoing bool
+	// Parallel is how many data calls Render makes at once. Defaults to 4,
+	// 1 makes them one after another.
+	Parallel int
+
+	// Logger logs every data call at the info level. Defaults to logging
+	// nothing.
+	Logger *slog.Logger
+}
+
+// Scribe renders templates. It's safe for concurrent use, also by renders of
+// the same template, and the results of data calls are shared by all its
+// renders.
+type Scribe struct {
+	opts Options
+	// sources are the enabled sources, by name
+	sources map[string]Source
+	state   *state
+}
+
+// state is shared by a Scribe and the ones derived from it.
+type state struct {
+	// memo holds the results of data calls, so templates and renders asking
+	// for the same data don't fetch it twice.
+	memo   map[string]*memoEntry
+	memoMu sync.Mutex
+
+	failures   []Failure
+	failuresMu sync.Mutex
+
+	timings timings
+
+	// viewer is the login of the token's user, once looked up
+	viewer   string
+	viewerMu sync.Mutex
+}
+
+// New returns a Scribe configured with opts.
+func New(opts Options) *Scribe {
+	if opts.Sources == nil {
+		for _, src := range Sources() {
+			if err := src.Configure(SourceConfig{}); err == nil {
+				opts.Sources = append(opts.Sources, src)
+			}
+		}
+	}
+	if opts.Parallel == 0 {
+		opts.Parallel = defaultParallel
+	}
+
+	sources := map[string]Source{}
+	for _, src := range opts.Sources {
+		sources[src.Name()] = src
+	}
+
+	return &Scribe{
+		opts:    opts,
+		sources: sources,
+		state:   &state{memo: map[string]*memoEntry{}},
+	}
+}
+
+// WithUsername returns a Scribe describing the GitHub user name, which shares
+// its clients, results and failures with s.
+func (s *Scribe) WithUsername(name string) *Scribe {
+	c := *s
+	c.opts.Username = name
+	return &c
+}
+
+// Username returns the GitHub user the data functions describe. Unless set
+// in the options, it's the user the github source's token belongs to, which
+// gets looked up until a lookup succeeds.
+func (s *Scribe) Username(ctx context.Context) (string, error) {
+	if len(s.opts.Username) > 0 {
+		return s.opts.Username, nil
+	}
+
+	g, ok := s.sources["github"].(*gitHub)
+	if !ok {
+		return "", errors.New(`can't look up the GitHub user: source "github" is disabled`)
+	}
+	s.state.viewerMu.Lock()
+	defer s.state.viewerMu.Unlock()
+	if len(s.state.viewer) > 0 {
+		return s.state.viewer, nil
+	}
+
+	login, err := g.getUsername(ctx)
+	if err != nil {
+		if len(g.cfg.Settings["token"]) == 0 {
+			return "", errors.New("no GitHub token to look up the user with: set one, or name the user to describe")
+		}
+		return "", err
+	}
+	s.state.viewer = login
+	return login, nil
+}
+
+// Check checks the enabled sources called names, or all enabled sources if
+// there are none, and returns the first error.
+func (s *Scribe) Check(ctx context.Context, names ...string) error {
+	if len(names) == 0 {
+		for _, src := range s.opts.Sources {
+			names = append(names, src.Name())
+		}
+	}
+
+	for _, name := range names {
+		src, ok := s.sources[name]
+		if !ok {
+			return fmt.Errorf("source %q is disabled", name)
+		}
+
+		checkCtx, cancel := s.sourceContext(ctx, name)
+		err := src.Check(checkCtx)
+		if err != nil && checkCtx.Err() != nil {
+			err = s.contextError(ctx, name)
+		} else if err != nil {
+			err = fmt.Errorf("%s: %w", name, err)
+		}
+		cancel()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// dataFuncs returns the template functions fetching data from sources for
+// renders with ctx, memoized but without any error handling. Registered
+// sources that aren't enabled get functions that fail when called.
+func (s *Scribe) dataFuncs(ctx context.Context) template.FuncMap {
+	data := template.FuncMap{}
+	for _, src := range Sources() {
+		if _, ok := s.sources[src.Name()]; ok {
+			continue
+		}
+		for name, fn := range src.Funcs(s) {
+			data[name] = s.bindContext(ctx, src.Name(), name, disabledFunc(src.Name(), fn))
+		}
+	}
+	for _, src := range s.opts.Sources {
+		for name, fn := range src.Funcs(s) {
+			data[name] = s.bindContext(ctx, src.Name(), name, fn)
+		}
+	}
+
+	for name, fn := range data {
+		data[name] = s.memoize(name, fn)
+	}
+	return data
+}
+
+// templateFuncs returns the template functions fetching data for renders
+// with ctx, wrapped in the configured error handling, and try.
+func (s *Scribe) templateFuncs(ctx context.Context) template.FuncMap {
+	funcMap := template.FuncMap{}
+	data := s.dataFuncs(ctx)
+	for name, fn := range data {
+		funcMap[name] = fn
+		if s.opts.KeepGoing {
+			funcMap[name] = s.continueOnError(name, fn)
+		}
+	}
+	funcMap["try"] = s.tryFunc(data)
+	return funcMap
+}
+
+// FuncMap returns the functions available to templates.
+func (s *Scribe) FuncMap() template.FuncMap {
+	return s.FuncMapContext(context.Background())
+}
+
+// FuncMapContext returns the functions available to templates, whose data
+// calls stop once ctx is done.
+func (s *Scribe) FuncMapContext(ctx context.Context) template.FuncMap {
+	funcMap := sprout.FuncMap(sprout.WithAlias("lower", "toLower"))
+	for name, fn := range s.templateFuncs(ctx) {
+		funcMap[name] = fn
+	}
+
+	/* Utils */
+	funcMap["humanize"] = Humanize
+
+	return funcMap
+}
+
+// Parse parses text as a template called name, with all of the functions of
+// FuncMap available.
+func (s *Scribe) Parse(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(s.FuncMap()).Parse(text)
+}
+
+// Prepare returns a copy of tpl and its associated templates whose data
+// functions are bound to ctx. It then fetches the data the named templates
+// are going to ask for, concurrently and batched where possible, so
+// rendering them doesn't have to wait for one call after another. tpl itself
+// is left alone, so concurrent renders can share it.
+func (s *Scribe) Prepare(ctx context.Context, tpl *template.Template, data interface{}, names ...string) (*template.Template, error) {
+	tpl, err := tpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tpl.Funcs(s.templateFuncs(ctx))
+	if ctx.Err() != nil {
+		return tpl, nil
+	}
+
+	prefetch(tpl, s.dataFuncs(ctx), s.opts.Parallel, names...)
+	if g, ok := s.sources["github"].(*gitHub); ok {
+		g.bind(s).batchRepoLookups(ctx, tpl, data, names...)
+	}
+	return tpl, nil
+}
+
+// Render executes tpl with data and writes the output to w. Data calls stop
+// once ctx is done.
+func (s *Scribe) Render(ctx context.Context, tpl *template.Template, w io.Writer, data interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	tpl, err := s.Prepare(ctx, tpl, data, tpl.Name())
+	if err != nil {
+		return err
+	}
+	return tpl.Execute(w, data)
+}
+
+// Call calls the data function name with args and returns its result. The
+// args get converted to the function's parameter types where possible, e.g.
+// "10" to an int. The call stops once ctx is done.
+func (s *Scribe) Call(ctx context.Context, name string, args ...interface{}) (interface{}, error) {
+	fn, ok := s.dataFuncs(ctx)[name]
+	if !ok {
+		return nil, fmt.Errorf("%q is not a data function", name)
+	}
+	in, err := callArgs(name, fn, args)
+	if err != nil {
+		return nil, err
+	}
+
+	res := reflect.ValueOf(fn).Call(in)
+	if err, ok := res[len(res)-1].Interface().(error); ok && err != nil {
+		return nil, err
+	}
+	return res[0].Interface(), nil
+}
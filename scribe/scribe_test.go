@@ -0,0 +1,93 @@
+package scribe
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestUsernameRetriesFailedLookups(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"data":{"viewer":{"login":"me"}}}`) //nolint: errcheck
+	}))
+	defer srv.Close()
+
+	src, err := NewSource("github", SourceConfig{
+		Client:   srv.Client(),
+		Settings: map[string]string{"url": srv.URL + "/graphql", "token": "secret"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := New(Options{Sources: []Source{src}})
+
+	if _, err := s.Username(context.Background()); err == nil {
+		t.Fatal("expected the first lookup to fail")
+	}
+	for i := 0; i < 2; i++ {
+		login, err := s.Username(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if login != "me" {
+			t.Errorf("login = %q, want %q", login, "me")
+		}
+	}
+
+	// only the failed lookup gets repeated
+	if n := requests.Load(); n != 2 {
+		t.Errorf("server got %d requests, want 2", n)
+	}
+}
+
+func TestRenderLeavesTemplateAlone(t *testing.T) {
+	alice := New(Options{Sources: []Source{&fakeSource{}}, Username: "alice"})
+	bob := New(Options{Sources: []Source{&fakeSource{}}, Username: "bob"})
+
+	tpl, err := alice.Parse("test", `{{whoami}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// renders sharing tpl each use their own functions
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for s, want := range map[*Scribe]string{alice: "alice", bob: "bob"} {
+			wg.Add(1)
+			go func(s *Scribe, want string) {
+				defer wg.Done()
+				var b strings.Builder
+				if err := s.Render(context.Background(), tpl, &b, nil); err != nil {
+					t.Error(err)
+					return
+				}
+				if b.String() != want {
+					t.Errorf("rendered %q, want %q", b.String(), want)
+				}
+			}(s, want)
+		}
+	}
+	wg.Wait()
+
+	var b strings.Builder
+	if err := bob.Render(context.Background(), tpl, io.Discard, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := tpl.Execute(&b, nil); err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != "alice" {
+		t.Errorf("tpl renders %q after the renders, want %q", b.String(), "alice")
+	}
+}
@@ -0,0 +1,137 @@
+package scribe
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+// Source is a service templates fetch data from, like GitHub or RSS feeds.
+type Source interface {
+	// Name identifies the source, e.g. "github".
+	Name() string
+	// Settings lists the settings the source gets configured with.
+	Settings() []Setting
+	// Configure sets the source up. It's called once, before anything else
+	// but Name and Settings.
+	Configure(cfg SourceConfig) error
+	// Funcs returns the template functions fetching data from the source,
+	// for the renders of s. Their first parameter is a context.Context,
+	// which templates don't pass themselves: each call gets the context of
+	// the render, limited to the source's timeout.
+	Funcs(s *Scribe) template.FuncMap
+	// Check reports whether the source is configured correctly and
+	// reachable.
+	Check(ctx context.Context) error
+}
+
+// Setting is a setting of a source, like an API key.
+type Setting struct {
+	// Name identifies the setting in SourceConfig.Settings.
+	Name string
+	// Env is the environment variable the markscribe command reads the
+	// setting from.
+	Env string
+	// Required settings need to be set for the source to work at all.
+	Required bool
+}
+
+// SourceConfig configures a source.
+type SourceConfig struct {
+	// Client makes the source's HTTP requests. Defaults to
+	// http.DefaultClient.
+	Client *http.Client
+	// Settings holds the values of the source's settings, by name.
+	Settings map[string]string
+}
+
+// client returns the HTTP client of the source.
+func (cfg SourceConfig) client() *http.Client {
+	if cfg.Client == nil {
+		return http.DefaultClient
+	}
+	return cfg.Client
+}
+
+var (
+	// registry holds a constructor for every kind of source, by name.
+	registry   = map[string]func() Source{}
+	registryMu sync.Mutex
+)
+
+// RegisterSource makes the sources returned by newSource available by their
+// name. It panics if a source of that name is already registered.
+func RegisterSource(newSource func() Source) {
+	name := newSource().Name()
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("source %q registered twice", name))
+	}
+	registry[name] = newSource
+}
+
+// Sources returns a new, unconfigured instance of every registered source,
+// sorted by name.
+func Sources() []Source {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	sources := make([]Source, 0, len(registry))
+	for _, newSource := range registry {
+		sources = append(sources, newSource())
+	}
+	sort.Slice(sources, func(i, j int) bool {
+		return sources[i].Name() < sources[j].Name()
+	})
+	return sources
+}
+
+// NewSource returns the registered source called name, configured with cfg.
+func NewSource(name string, cfg SourceConfig) (Source, error) {
+	registryMu.Lock()
+	newSource, ok := registry[name]
+	registryMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown source %q", name)
+	}
+
+	src := newSource()
+	if err := src.Configure(cfg); err != nil {
+		return nil, fmt.Errorf("can't configure %s: %w", name, err)
+	}
+	return src, nil
+}
+
+// missingSettings returns an error listing the required settings of src
+// that cfg doesn't set, if any.
+func missingSettings(src Source, cfg SourceConfig) error {
+	var missing []string
+	for _, s := range src.Settings() {
+		if s.Required && len(cfg.Settings[s.Name]) == 0 {
+			missing = append(missing, s.Name)
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("missing settings: %s", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// disabledFunc returns a template function standing in for the function fn
+// of the disabled source called name. It fails when called, but lets
+// templates using it parse.
+func disabledFunc(name string, fn interface{}) interface{} {
+	t := reflect.TypeOf(fn)
+	return reflect.MakeFunc(t, func([]reflect.Value) []reflect.Value {
+		err := fmt.Errorf("source %q is disabled", name)
+		return []reflect.Value{reflect.Zero(t.Out(0)), reflect.ValueOf(&err).Elem()}
+	}).Interface()
+}
@@ -0,0 +1,112 @@
+package scribe
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/shurcooL/githubv4"
+)
+
+type sponsorsQuery struct {
+	Owner struct {
+		Login githubv4.String
+		// users and organizations can both be sponsored
+		Sponsorable struct {
+			SponsorshipsAsMaintainer struct {
+				TotalCount githubv4.Int
+				Edges      []struct {
+					Cursor githubv4.String
+					Node   struct {
+						CreatedAt     githubv4.DateTime
+						SponsorEntity struct {
+							Typename     githubv4.String `graphql:"__typename"`
+							User         qlUser          `graphql:"... on User"`
+							Organization qlUser          `graphql:"... on Organization"`
+						}
+					}
+				}
+				PageInfo qlPageInfo
+			} `graphql:"sponsorshipsAsMaintainer(first: $count, after: $after, orderBy: {field: CREATED_AT, direction: DESC})"`
+		} `graphql:"... on Sponsorable"`
+	} `graphql:"repositoryOwner(login:$username)"`
+}
+
+func (g *gitHub) sponsors(ctx context.Context, count int) ([]Sponsor, error) {
+	login, err := g.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.sponsorsOf(ctx, login, count)
+}
+
+func (g *gitHub) sponsorsOf(ctx context.Context, login string, count int) ([]Sponsor, error) {
+	return paginate(count, func(first int, after *githubv4.String) ([]Sponsor, qlPageInfo, error) {
+		var query sponsorsQuery
+		variables := map[string]interface{}{
+			"username": githubv4.String(login),
+			"count":    githubv4.Int(first),
+			"after":    after,
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, qlPageInfo{}, err
+		}
+
+		// unknown logins resolve to nothing rather than an error
+		if len(query.Owner.Login) == 0 {
+			return nil, qlPageInfo{}, fmt.Errorf("could not resolve to a user or organization with the login %q", login)
+		}
+
+		sponsorships := query.Owner.Sponsorable.SponsorshipsAsMaintainer
+		var sponsors []Sponsor
+		for _, v := range sponsorships.Edges {
+			switch v.Node.SponsorEntity.Typename {
+			case "User":
+				sponsors = append(sponsors, Sponsor{
+					User:      userFromQL(v.Node.SponsorEntity.User),
+					CreatedAt: v.Node.CreatedAt.Time,
+				})
+			case "Organization":
+				sponsors = append(sponsors, Sponsor{
+					User:      userFromQL(v.Node.SponsorEntity.Organization),
+					CreatedAt: v.Node.CreatedAt.Time,
+				})
+			}
+		}
+		return sponsors, sponsorships.PageInfo, nil
+	})
+}
+
+/*
+{
+  repositoryOwner(login: "muesli") {
+    login
+    ... on Sponsorable {
+      sponsorshipsAsMaintainer(first: 100) {
+        totalCount
+        edges {
+          cursor
+          node {
+            createdAt
+            sponsorEntity {
+              __typename
+              ... on User {
+                login
+                name
+                avatarUrl
+                url
+              }
+              ... on Organization {
+                login
+                name
+                avatarUrl
+                url
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}
+*/
@@ -1,4 +1,4 @@
-package main
+package scribe
 
 import (
 	"context"
@@ -6,7 +6,7 @@ import (
 	"github.com/shurcooL/githubv4"
 )
 
-var recentStarsQuery struct {
+type recentStarsQuery struct {
 	User struct {
 		Login githubv4.String
 		Stars struct {
@@ -16,27 +16,34 @@ var recentStarsQuery struct {
 				StarredAt githubv4.DateTime
 				Node      qlRepository
 			}
+			PageInfo qlPageInfo
 		} `graphql:"starredRepositories(first: $count, after:$after, orderBy: {field: STARRED_AT, direction: DESC})"`
 	} `graphql:"user(login:$username)"`
 }
 
-func recentStars(count int) []Star {
-	var starredRepos []Star
-	var after *githubv4.String
+func (g *gitHub) recentStars(ctx context.Context, count int) ([]Star, error) {
+	login, err := g.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.recentStarsOf(ctx, login, count)
+}
 
-outer:
-	for {
+func (g *gitHub) recentStarsOf(ctx context.Context, login string, count int) ([]Star, error) {
+	return paginate(count, func(first int, after *githubv4.String) ([]Star, qlPageInfo, error) {
+		var query recentStarsQuery
 		variables := map[string]interface{}{
-			"username": githubv4.String(username),
-			"count":    githubv4.Int(count),
+			"username": githubv4.String(login),
+			"count":    githubv4.Int(first),
 			"after":    after,
 		}
-		err := gitHubClient.Query(context.Background(), &recentStarsQuery, variables)
+		err := g.query(ctx, &query, variables)
 		if err != nil {
-			panic(err)
+			return nil, qlPageInfo{}, err
 		}
 
-		for _, v := range recentStarsQuery.User.Stars.Edges {
+		var starredRepos []Star
+		for _, v := range query.User.Stars.Edges {
 			if v.Node.IsPrivate {
 				continue
 			}
@@ -44,14 +51,9 @@ outer:
 				StarredAt: v.StarredAt.Time,
 				Repo:      repoFromQL(v.Node),
 			})
-			if len(starredRepos) >= count {
-				break outer
-			}
-			after = githubv4.NewString(v.Cursor)
 		}
-	}
-
-	return starredRepos
+		return starredRepos, query.User.Stars.PageInfo, nil
+	})
 }
 
 /*
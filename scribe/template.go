@@ -1,4 +1,4 @@
-package main
+package scribe
 
 import (
 	"fmt"
@@ -7,7 +7,9 @@ import (
 	"github.com/dustin/go-humanize"
 )
 
-func humanized(t interface{}) string {
+// Humanize formats t for humans: times relative to now, in days, and numbers
+// with thousands separators. Anything else is formatted with %v.
+func Humanize(t interface{}) string {
 	switch v := t.(type) {
 	case time.Time:
 		// flatten time to prevent updating README too often:
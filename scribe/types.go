@@ -1,4 +1,4 @@
-package main
+package scribe
 
 import (
 	"time"
@@ -35,6 +35,25 @@ type PullRequest struct {
 	Repo      Repo
 }
 
+// Issue represents an issue.
+type Issue struct {
+	Title     string
+	URL       string
+	State     string
+	Labels    []string
+	Comments  int
+	CreatedAt time.Time
+	Repo      Repo
+}
+
+// IssueComment represents a comment on an issue.
+type IssueComment struct {
+	Body      string
+	URL       string
+	CreatedAt time.Time
+	Issue     Issue
+}
+
 // Release represents a release.
 type Release struct {
 	Name         string
@@ -88,6 +107,29 @@ type qlPullRequest struct {
 	Repository qlRepository
 }
 
+type qlIssue struct {
+	URL       githubv4.String
+	Title     githubv4.String
+	State     githubv4.IssueState
+	CreatedAt githubv4.DateTime
+	Labels    struct {
+		Nodes []struct {
+			Name githubv4.String
+		}
+	} `graphql:"labels(first: 10)"`
+	Comments struct {
+		TotalCount githubv4.Int
+	}
+	Repository qlRepository
+}
+
+type qlIssueComment struct {
+	Body      githubv4.String
+	URL       githubv4.String
+	CreatedAt githubv4.DateTime
+	Issue     qlIssue
+}
+
 type qlRelease struct {
 	Name         githubv4.String
 	TagName      githubv4.String
@@ -152,6 +194,31 @@ func pullRequestFromQL(pullRequest qlPullRequest) PullRequest {
 	}
 }
 
+func issueFromQL(issue qlIssue) Issue {
+	labels := make([]string, 0, len(issue.Labels.Nodes))
+	for _, l := range issue.Labels.Nodes {
+		labels = append(labels, string(l.Name))
+	}
+	return Issue{
+		Title:     string(issue.Title),
+		URL:       string(issue.URL),
+		State:     string(issue.State),
+		Labels:    labels,
+		Comments:  int(issue.Comments.TotalCount),
+		CreatedAt: issue.CreatedAt.Time,
+		Repo:      repoFromQL(issue.Repository),
+	}
+}
+
+func issueCommentFromQL(comment qlIssueComment) IssueComment {
+	return IssueComment{
+		Body:      string(comment.Body),
+		URL:       string(comment.URL),
+		CreatedAt: comment.CreatedAt.Time,
+		Issue:     issueFromQL(comment.Issue),
+	}
+}
+
 func releaseFromQL(release qlRelease) Release {
 	return Release{
 		Name:        string(release.Name),
@@ -0,0 +1,87 @@
+package scribe
+
+import (
+	"context"
+
+	"github.com/shurcooL/githubv4"
+)
+
+type viewerQuery struct {
+	Viewer struct {
+		Login githubv4.String
+	}
+}
+
+type recentFollowersQuery struct {
+	User struct {
+		Login     githubv4.String
+		Followers struct {
+			TotalCount githubv4.Int
+			Edges      []struct {
+				Cursor githubv4.String
+				Node   qlUser
+			}
+			PageInfo qlPageInfo
+		} `graphql:"followers(first: $count, after: $after)"`
+	} `graphql:"user(login:$username)"`
+}
+
+func (g *gitHub) getUsername(ctx context.Context) (string, error) {
+	var query viewerQuery
+	err := g.query(ctx, &query, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(query.Viewer.Login), nil
+}
+
+func (g *gitHub) recentFollowers(ctx context.Context, count int) ([]User, error) {
+	login, err := g.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return g.recentFollowersOf(ctx, login, count)
+}
+
+func (g *gitHub) recentFollowersOf(ctx context.Context, login string, count int) ([]User, error) {
+	return paginate(count, func(first int, after *githubv4.String) ([]User, qlPageInfo, error) {
+		var query recentFollowersQuery
+		variables := map[string]interface{}{
+			"username": githubv4.String(login),
+			"count":    githubv4.Int(first),
+			"after":    after,
+		}
+		err := g.query(ctx, &query, variables)
+		if err != nil {
+			return nil, qlPageInfo{}, err
+		}
+
+		var users []User
+		for _, v := range query.User.Followers.Edges {
+			users = append(users, userFromQL(v.Node))
+		}
+		return users, query.User.Followers.PageInfo, nil
+	})
+}
+
+/*
+{
+  user(login: "muesli") {
+    login
+    followers(first: 10) {
+      totalCount
+      edges {
+        cursor
+        node {
+          id
+          avatarUrl
+          login
+		  name
+		  url
+        }
+      }
+    }
+  }
+}
+*/
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// markerRe matches the comments delimiting a section that markscribe owns,
+// e.g. <!-- markscribe:start stars --> and <!-- markscribe:end stars -->.
+var markerRe = regexp.MustCompile(`<!--\s*markscribe:(start|end)\s+([\w.-]+)\s*-->`)
+
+// section is a marked region of a document. start and end are the byte
+// offsets of the content between the two markers.
+type section struct {
+	name       string
+	start, end int
+}
+
+// lineAt returns the 1-based line number of offset in doc.
+func lineAt(doc []byte, offset int) int {
+	return bytes.Count(doc[:offset], []byte("\n")) + 1
+}
+
+// findSections returns all marked sections of doc in document order. Nested,
+// duplicate, unbalanced or mismatched markers are reported as errors.
+func findSections(doc []byte) ([]section, error) {
+	var sections []section
+	var open *section
+	seen := map[string]bool{}
+
+	for _, m := range markerRe.FindAllSubmatchIndex(doc, -1) {
+		kind := string(doc[m[2]:m[3]])
+		name := string(doc[m[4]:m[5]])
+		line := lineAt(doc, m[0])
+
+		switch kind {
+		case "start":
+			if open != nil {
+				return nil, fmt.Errorf("line %d: section %q starts before section %q ends", line, name, open.name)
+			}
+			if seen[name] {
+				return nil, fmt.Errorf("line %d: duplicate section %q", line, name)
+			}
+			seen[name] = true
+			open = &section{name: name, start: m[1]}
+		case "end":
+			if open == nil {
+				return nil, fmt.Errorf("line %d: end of section %q without a start", line, name)
+			}
+			if open.name != name {
+				return nil, fmt.Errorf("line %d: end of section %q, but section %q is open", line, name, open.name)
+			}
+			open.end = m[0]
+			sections = append(sections, *open)
+			open = nil
+		}
+	}
+
+	if open != nil {
+		return nil, fmt.Errorf("line %d: section %q is never closed", lineAt(doc, open.start), open.name)
+	}
+	if len(sections) == 0 {
+		return nil, fmt.Errorf("no markscribe sections found")
+	}
+	return sections, nil
+}
+
+// spliceSections replaces the content of every marked section in doc with the
+// output of render for that section's name. Everything outside the sections,
+// including the markers themselves, is preserved as is.
+func spliceSections(doc []byte, render func(name string) (string, error)) ([]byte, error) {
+	sections, err := findSections(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	var last int
+	for _, s := range sections {
+		out, err := render(s.name)
+		if err != nil {
+			return nil, fmt.Errorf("section %q: %w", s.name, err)
+		}
+
+		buf.Write(doc[last:s.start])
+		buf.WriteString("\n")
+		if out = strings.Trim(out, "\n"); out != "" {
+			buf.WriteString(out)
+			buf.WriteString("\n")
+		}
+		last = s.end
+	}
+	buf.Write(doc[last:])
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFindSections(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		want  []string
+		error string
+	}{
+		{
+			name: "single",
+			doc:  "# Me\n<!-- markscribe:start stars -->\nold\n<!-- markscribe:end stars -->\n",
+			want: []string{"stars"},
+		},
+		{
+			name: "several",
+			doc:  "<!-- markscribe:start a -->\n<!-- markscribe:end a -->\ntext\n<!--markscribe:start b.c-->x<!--markscribe:end b.c-->",
+			want: []string{"a", "b.c"},
+		},
+		{
+			name:  "missing",
+			doc:   "# Me\nno markers here\n",
+			error: "no markscribe sections found",
+		},
+		{
+			name:  "nested",
+			doc:   "<!-- markscribe:start a -->\n<!-- markscribe:start b -->\n<!-- markscribe:end b -->\n<!-- markscribe:end a -->\n",
+			error: `line 2: section "b" starts before section "a" ends`,
+		},
+		{
+			name:  "unterminated",
+			doc:   "\n<!-- markscribe:start a -->\nold\n",
+			error: `line 2: section "a" is never closed`,
+		},
+		{
+			name:  "end without start",
+			doc:   "<!-- markscribe:end a -->\n",
+			error: `line 1: end of section "a" without a start`,
+		},
+		{
+			name:  "mismatched",
+			doc:   "<!-- markscribe:start a -->\n<!-- markscribe:end b -->\n",
+			error: `line 2: end of section "b", but section "a" is open`,
+		},
+		{
+			name:  "duplicate",
+			doc:   "<!-- markscribe:start a -->\n<!-- markscribe:end a -->\n<!-- markscribe:start a -->\n<!-- markscribe:end a -->\n",
+			error: `line 3: duplicate section "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sections, err := findSections([]byte(tt.doc))
+			if len(tt.error) > 0 {
+				if err == nil || err.Error() != tt.error {
+					t.Fatalf("err = %v, want %q", err, tt.error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, s := range sections {
+				names = append(names, s.name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("sections = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpliceSections(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc    string
+		output map[string]string
+		want   string
+		error  string
+	}{
+		{
+			name:   "replace",
+			doc:    "# Me\n<!-- markscribe:start stars -->\nold\n<!-- markscribe:end stars -->\nfooter\n",
+			output: map[string]string{"stars": "new"},
+			want:   "# Me\n<!-- markscribe:start stars -->\nnew\n<!-- markscribe:end stars -->\nfooter\n",
+		},
+		{
+			name:   "trims newlines",
+			doc:    "<!-- markscribe:start a -->old<!-- markscribe:end a -->",
+			output: map[string]string{"a": "\n\nnew\n\n"},
+			want:   "<!-- markscribe:start a -->\nnew\n<!-- markscribe:end a -->",
+		},
+		{
+			name:   "empty",
+			doc:    "<!-- markscribe:start a -->\nold\n<!-- markscribe:end a -->\n",
+			output: map[string]string{"a": ""},
+			want:   "<!-- markscribe:start a -->\n<!-- markscribe:end a -->\n",
+		},
+		{
+			name:   "several",
+			doc:    "<!-- markscribe:start a -->\n1\n<!-- markscribe:end a -->\nkeep\n<!-- markscribe:start b -->\n2\n<!-- markscribe:end b -->\n",
+			output: map[string]string{"a": "one", "b": "two"},
+			want:   "<!-- markscribe:start a -->\none\n<!-- markscribe:end a -->\nkeep\n<!-- markscribe:start b -->\ntwo\n<!-- markscribe:end b -->\n",
+		},
+		{
+			name:  "unterminated",
+			doc:   "<!-- markscribe:start a -->\nold\n",
+			error: `line 1: section "a" is never closed`,
+		},
+		{
+			name:   "render error",
+			doc:    "<!-- markscribe:start a -->\n<!-- markscribe:end a -->\n",
+			output: map[string]string{},
+			error:  `section "a": no template named "a"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := spliceSections([]byte(tt.doc), func(name string) (string, error) {
+				s, ok := tt.output[name]
+				if !ok {
+					return "", errors.New(`no template named "` + name + `"`)
+				}
+				return s, nil
+			})
+			if len(tt.error) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.error) {
+					t.Fatalf("err = %v, want %q", err, tt.error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tt.want {
+				t.Errorf("spliceSections() = %q, want %q", out, tt.want)
+			}
+		})
+	}
+}
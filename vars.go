@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Version is the version of markscribe, set at build time.
+var Version = ""
+
+// Meta describes the current render. Templates access it as .Meta.
+type Meta struct {
+	// Username is the GitHub user that user-scoped functions describe.
+	Username string
+	// GeneratedAt is when the render started.
+	GeneratedAt time.Time
+	// Version is the version of markscribe.
+	Version string
+}
+
+// varFlag holds the variables set with -var, in order.
+type varFlag []string
+
+func (f *varFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+// Set parses a key=value pair.
+func (f *varFlag) Set(s string) error {
+	if k, _, ok := strings.Cut(s, "="); !ok || len(k) == 0 {
+		return fmt.Errorf("%q is not a key=value pair", s)
+	}
+	*f = append(*f, s)
+	return nil
+}
+
+// loadVars reads a YAML file of variables.
+func loadVars(path string) (map[string]interface{}, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := map[string]interface{}{}
+	if err := yaml.NewDecoder(bytes.NewReader(b)).Decode(&vars); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return vars, nil
+}
+
+// templateData returns the data a template gets executed with: the job's
+// variables, overridden by those of -vars and -var, alongside the
+// environment as .Env and a description of the render as .Meta.
+func templateData(j job) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	for k, v := range j.Vars {
+		data[k] = v
+	}
+	for k, v := range fileVars {
+		data[k] = v
+	}
+	for _, kv := range varFlags {
+		k, v, _ := strings.Cut(kv, "=")
+		data[k] = v
+	}
+
+	for _, k := range []string{"Env", "Meta"} {
+		if _, ok := data[k]; ok {
+			return nil, fmt.Errorf("can't use reserved variable name %q", k)
+		}
+	}
+
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+	data["Env"] = env
+
+	data["Meta"] = Meta{
+		Username:    jobUsername(j),
+		GeneratedAt: time.Now(),
+		Version:     version(),
+	}
+	return data, nil
+}
+
+// version returns the version of markscribe, falling back to the module
+// version for builds that didn't set Version.
+func version() string {
+	if len(Version) > 0 {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "unknown"
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTemplateData(t *testing.T) {
+	tests := []struct {
+		name     string
+		jobVars  map[string]interface{}
+		fileVars map[string]interface{}
+		varFlags varFlag
+		// want are the variables besides Env and Meta
+		want  map[string]interface{}
+		error string
+	}{
+		{
+			name:    "job",
+			jobVars: map[string]interface{}{"name": "Christian", "count": 5},
+			want:    map[string]interface{}{"name": "Christian", "count": 5},
+		},
+		{
+			name:     "vars file overrides job",
+			jobVars:  map[string]interface{}{"name": "Christian", "count": 5},
+			fileVars: map[string]interface{}{"count": 10},
+			want:     map[string]interface{}{"name": "Christian", "count": 10},
+		},
+		{
+			name:     "var flags override all",
+			jobVars:  map[string]interface{}{"name": "Christian", "count": 5},
+			fileVars: map[string]interface{}{"name": "Chris", "count": 10},
+			varFlags: varFlag{"count=20", "name=muesli", "empty=", "eq=a=b"},
+			want:     map[string]interface{}{"name": "muesli", "count": "20", "empty": "", "eq": "a=b"},
+		},
+		{
+			name:     "last var flag wins",
+			varFlags: varFlag{"name=a", "name=b"},
+			want:     map[string]interface{}{"name": "b"},
+		},
+		{
+			name:    "reserved Env",
+			jobVars: map[string]interface{}{"Env": "x"},
+			error:   `can't use reserved variable name "Env"`,
+		},
+		{
+			name:     "reserved Meta",
+			fileVars: map[string]interface{}{"Meta": "x"},
+			error:    `can't use reserved variable name "Meta"`,
+		},
+		{
+			name:     "reserved by flag",
+			varFlags: varFlag{"Meta=x"},
+			error:    `can't use reserved variable name "Meta"`,
+		},
+		{
+			name:    "lower case is fine",
+			jobVars: map[string]interface{}{"env": "x", "meta": "y"},
+			want:    map[string]interface{}{"env": "x", "meta": "y"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(v map[string]interface{}, f varFlag) {
+				fileVars, varFlags = v, f
+			}(fileVars, varFlags)
+			fileVars, varFlags = tt.fileVars, tt.varFlags
+			t.Setenv("MARKSCRIBE_TEST", "set")
+
+			data, err := templateData(job{Template: "README.md.tpl", Owner: "muesli", Vars: tt.jobVars})
+			if len(tt.error) > 0 {
+				if err == nil || !strings.Contains(err.Error(), tt.error) {
+					t.Fatalf("error = %v, want %q", err, tt.error)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if env, _ := data["Env"].(map[string]string); env["MARKSCRIBE_TEST"] != "set" {
+				t.Errorf(".Env = %v, want it to hold the environment", data["Env"])
+			}
+			if meta, _ := data["Meta"].(Meta); meta.Username != "muesli" || meta.GeneratedAt.IsZero() {
+				t.Errorf(".Meta = %+v, want it to describe the render", data["Meta"])
+			}
+			delete(data, "Env")
+			delete(data, "Meta")
+			if !reflect.DeepEqual(data, tt.want) {
+				t.Errorf("data = %v, want %v", data, tt.want)
+			}
+		})
+	}
+}